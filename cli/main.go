@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"puzzle_solver/solver"
 	"runtime"
 	"strconv"
@@ -11,41 +18,410 @@ import (
 	"time"
 )
 
-func getMonthName(monthInput string, months []string) (string, error) {
-	if monthInput == "" {
-		return "", fmt.Errorf("invalid month: empty string")
+// targetDate returns the date to solve. Boards without weekday cells ignore the
+// year, so any valid day of the month is accepted there, including 29 February.
+func targetDate(s *solver.CalendarBoardSolver, year, day int, month time.Month) (time.Time, error) {
+	if len(s.WeekdayPositions) == 0 {
+		year = 0
 	}
-	// If it's already a Russian month name
-	for _, month := range months {
-		if monthInput == month {
-			return month, nil
-		}
+	if err := s.CheckDate(year, month, day); err != nil {
+		return time.Time{}, err
+	}
+	if year == 0 {
+		year = 2024 // A leap year, so that 29 February exists
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local), nil
+}
+
+// puzzle is what the CLI solves: a date, or the cells given with -block.
+type puzzle struct {
+	date    time.Time
+	blocked cellList
+}
+
+func (p puzzle) label(s *solver.CalendarBoardSolver) string {
+	if p.blocked != nil {
+		return "blocked cells " + p.blocked.String()
+	}
+	return s.FormatDate(p.date)
+}
+
+// cellList collects the repeatable -block flag, one "row,col" pair per use.
+type cellList []solver.Position
+
+func (cells *cellList) String() string {
+	if cells == nil {
+		return ""
+	}
+	parts := make([]string, len(*cells))
+	for i, cell := range *cells {
+		parts[i] = fmt.Sprintf("%d,%d", cell.Row, cell.Col)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (cells *cellList) Set(value string) error {
+	row, col, found := strings.Cut(value, ",")
+	r, rowErr := strconv.Atoi(strings.TrimSpace(row))
+	c, colErr := strconv.Atoi(strings.TrimSpace(col))
+	if !found || rowErr != nil || colErr != nil {
+		return fmt.Errorf("invalid cell %q, expected row,col", value)
+	}
+	*cells = append(*cells, solver.Position{Row: r, Col: c})
+	return nil
+}
+
+// commandLineTarget returns the puzzle given on the command line: blocked
+// cells, a date, or today's date when neither is given.
+func commandLineTarget(s *solver.CalendarBoardSolver, blocks cellList, day int, month string, year int) (puzzle, error) {
+	if len(blocks) > 0 {
+		return puzzle{blocked: blocks}, nil
+	}
+	if day == -1 || month == "" {
+		return puzzle{date: time.Now()}, nil
+	}
+
+	currentMonth, err := solver.ParseMonth(month)
+	if err != nil {
+		return puzzle{}, fmt.Errorf("%w\nAvailable months: %s", err, strings.Join(s.Months, ", "))
+	}
+	date, err := targetDate(s, year, day, currentMonth)
+	return puzzle{date: date}, err
+}
+
+// visualize prints a solution for a puzzle and where each piece goes.
+func visualize(s *solver.CalendarBoardSolver, p puzzle, result solver.SolveResult) {
+	if p.blocked != nil {
+		s.VisualizeBlockedSolution(p.blocked, result.PieceMap)
+	} else {
+		s.VisualizeSolution(p.date.Day(), s.Months[p.date.Month()-1], result.Solution, result.PieceMap)
+	}
+	s.PrintPlacements(result.Placements)
+}
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe
+// or a file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// useColor decides on coloured output for the -color flag. In auto mode it
+// follows https://no-color.org and only colours output going to a terminal.
+func useColor(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		return isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "", nil
+	}
+	return false, fmt.Errorf("invalid color mode: %s (expected auto, always or never)", mode)
+}
+
+// printProgress renders solver progress as a single status line on stderr and
+// clears it once the solve is done.
+func printProgress(p solver.Progress) {
+	if p.Done {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		return
 	}
 
-	// If it's a number (1-12)
-	if monthNum, err := strconv.Atoi(monthInput); err == nil {
-		if monthNum >= 1 && monthNum <= 12 {
-			return months[monthNum-1], nil
+	busy := 0
+	for _, worker := range p.Workers {
+		if !worker.Idle {
+			busy++
 		}
 	}
+	fmt.Fprintf(os.Stderr, "\r\033[K⏳ %.1fs | %d nodes | %.0f nodes/s | depth %d | %d/%d workers busy",
+		p.Elapsed.Seconds(), p.Attempts, p.NodesPerSecond, p.Depth, busy, len(p.Workers))
+}
+
+// solveWithTimeout runs a single solve bounded by the solver's timeout and by
+// ctx, which is cancelled on Ctrl-C.
+func solveWithTimeout(ctx context.Context, s *solver.CalendarBoardSolver, p puzzle) (solver.SolveResult, error) {
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	if p.blocked != nil {
+		return s.SolveBlockedContext(ctx, p.blocked)
+	}
+	return s.SolveDateContext(ctx, p.date)
+}
+
+// loadSolutionDB reads the database built into the solver package or one
+// written by gendb.
+func loadSolutionDB(path string) (*solver.SolutionDB, error) {
+	if path == "builtin" {
+		return solver.EmbeddedSolutionDB()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return solver.ReadSolutionDB(f)
+}
+
+// imageWriter is the signature of the solver's image renderers.
+type imageWriter func(io.Writer, solver.SolveResult, solver.RenderOptions) error
+
+// imageFormat returns the renderer for an image format. With -out and the
+// default -format, the format comes from the file extension.
+func imageFormat(s *solver.CalendarBoardSolver, format, out string) (imageWriter, error) {
+	if format == "text" && out != "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(out)), ".")
+	}
+	switch format {
+	case "svg":
+		return s.WriteSVG, nil
+	case "png":
+		return s.WritePNG, nil
+	case "gif":
+		return s.WriteGIF, nil
+	}
+	return nil, fmt.Errorf("invalid format: %s", format)
+}
+
+// saveImage writes a solution to a file as an image.
+func saveImage(path string, write imageWriter, result solver.SolveResult, opts solver.RenderOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, result, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeImage solves the puzzle and writes the solution as an image to out, or
+// to stdout when out is empty.
+func writeImage(ctx context.Context, s *solver.CalendarBoardSolver, p puzzle, write imageWriter, out string, opts solver.RenderOptions) {
+	result, err := solveWithTimeout(ctx, s, p)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if !result.Found {
+		log.Fatalf("Error: no solution for %s", p.label(s))
+	}
+	opts.Title = p.label(s)
+	if out != "" {
+		err = saveImage(out, write, result, opts)
+	} else {
+		err = write(os.Stdout, result, opts)
+	}
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func countSolutions(ctx context.Context, s *solver.CalendarBoardSolver, p puzzle) {
+	label := p.label(s)
+	var result solver.CountResult
+	var err error
+	if p.blocked != nil {
+		result, err = s.CountSolutionsBlockedContext(ctx, p.blocked)
+	} else {
+		result, err = s.CountSolutionsDateContext(ctx, p.date)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error: %v", err)
+	}
+	if err != nil {
+		fmt.Printf("\n✗ Interrupted: at least %d solutions for %s\n", result.Count, label)
+	} else {
+		fmt.Printf("\nNumber of solutions for %s: %d\n", label, result.Count)
+	}
+	if result.Precomputed {
+		fmt.Println("- Taken from the solution database")
+	}
+	fmt.Printf("- Search time: %.4f seconds\n", result.SolveTime.Seconds())
+	fmt.Printf("- Total attempts: %d\n", result.Attempts)
+	fmt.Printf("- Pruned placements: %d\n", result.Pruned)
+}
+
+func enumerateSolutions(ctx context.Context, s *solver.CalendarBoardSolver, p puzzle) {
+	label := p.label(s)
+	fmt.Printf("\nEnumerating all solutions for: %s\n", label)
 
-	// Try partial matching
-	for _, month := range months {
-		if strings.HasPrefix(strings.ToLower(month), strings.ToLower(monthInput)) {
-			return month, nil
+	solutions := make(chan solver.SolveResult)
+	done := make(chan solver.SolveAllResult)
+	var err error
+	go func() {
+		var summary solver.SolveAllResult
+		if p.blocked != nil {
+			summary, err = s.SolveAllStreamBlockedContext(ctx, p.blocked, solutions)
+		} else {
+			summary, err = s.SolveAllStreamDateContext(ctx, p.date, solutions)
 		}
+		done <- summary
+	}()
+
+	count := 0
+	for result := range solutions {
+		count++
+		fmt.Printf("\nSolution #%d (found after %.4f seconds, %d attempts)\n", count, result.SolveTime.Seconds(), result.Attempts)
+		visualize(s, p, result)
+	}
+	summary := <-done
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error: %v", err)
 	}
 
-	return "", fmt.Errorf("invalid month: %s", monthInput)
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	if err != nil {
+		fmt.Printf("✗ Interrupted after %d distinct solutions for %s\n", summary.Count, label)
+	} else {
+		fmt.Printf("Total distinct solutions for %s: %d\n", label, summary.Count)
+	}
+	fmt.Printf("- Search time: %.4f seconds\n", summary.SolveTime.Seconds())
+	fmt.Printf("- Total attempts: %d\n", summary.Attempts)
+	fmt.Printf("- Pruned placements: %d\n", summary.Pruned)
 }
 
 func main() {
 	var day = flag.Int("day", -1, "Day (1-31)")
-	var month = flag.String("month", "", "Month (Янв, Фев, Март, etc. or 1-12)")
+	var month = flag.String("month", "", "Month (1-12, or a month name or prefix in any locale: Янв, Mar, Sept...)")
+	var localeName = flag.String("locale", "ru", "Language of the board labels ("+strings.Join(solver.LocaleNames(), ", ")+")")
+	var year = flag.Int("year", time.Now().Year(), "Year, which sets the weekday on the weekday board")
+	var board = flag.String("board", "classic", "Board edition (classic, weekday or a board definition file)")
+	var pieces = flag.String("pieces", "", "Piece definition file replacing the board's built-in pieces")
+	var blocks cellList
+	flag.Var(&blocks, "block", "Cell to leave uncovered instead of a date, as row,col (repeatable)")
+	var format = flag.String("format", "text", "Output format: text report, or svg, png or gif (animated) for an image of the solution on stdout")
+	var out = flag.String("out", "", "Also write an image of the solution to this file (.png, .svg or .gif)")
+	var cellSize = flag.Int("cell-size", 0, "Pixels per board cell in images (0 = 60)")
+	var palette = flag.String("palette", "", "Comma-separated piece colours for images, e.g. #ff0000,#00ff00")
+	var delay = flag.Duration("delay", 0, "Time between the frames of a GIF animation (0 = 700ms)")
 	var testOnly = flag.Bool("test-only", false, "Skip main solve, run only test cases")
+	var all = flag.Bool("all", false, "Enumerate every solution for the date")
+	var count = flag.Bool("count", false, "Only count the solutions for the date")
+	var strategy = flag.String("strategy", "backtrack", "Search strategy (backtrack, cell or dlx)")
+	var workers = flag.Int("workers", 0, "Number of search workers (0 = number of CPU cores)")
+	var timeout = flag.Duration("timeout", solver.DefaultTimeout, "Time limit for a single solve (0 = no limit)")
+	var noFlip = flag.Bool("no-flip", false, "Only rotate pieces, never flip them over")
+	var noPrune = flag.Bool("no-prune", false, "Disable dead-region pruning")
+	var deterministic = flag.Bool("deterministic", false, "Always return the lexicographically first solution (enumerates them all)")
+	var dbPath = flag.String("db", "", "Answer from a solution database: \"builtin\" or a file written by gendb")
+	var seed = flag.Uint64("seed", 0, "Shuffle the placement order with this seed (0 = default order)")
+	var verbose = flag.Bool("verbose", false, "Log the solver's diagnostics to stderr")
+	var colorMode = flag.String("color", "auto", "Colour the solution and pieces: auto (terminals without NO_COLOR), always or never")
+	var progress = flag.Bool("progress", true, "Show a live status line on stderr while solving (terminals only, not with -all)")
 	flag.Parse()
 
-	s := solver.NewCalendarBoardSolver()
+	// Ctrl-C cancels the running solve and prints the statistics gathered so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	searchStrategy, err := solver.ParseStrategy(*strategy)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	locale, err := solver.LookupLocale(*localeName)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	opts := []solver.Option{
+		solver.WithLocale(locale),
+		solver.WithStrategy(searchStrategy),
+		solver.WithWorkers(*workers),
+		solver.WithTimeout(*timeout),
+	}
+	if *noFlip {
+		opts = append(opts, solver.WithOrientations(solver.OrientationsRotateOnly))
+	}
+	if *noPrune {
+		opts = append(opts, solver.WithPruning(false))
+	}
+	if *deterministic {
+		opts = append(opts, solver.WithDeterministic(true))
+	}
+	if *seed != 0 {
+		opts = append(opts, solver.WithRandomSeed(*seed))
+	}
+	if *dbPath != "" {
+		db, err := loadSolutionDB(*dbPath)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		opts = append(opts, solver.WithSolutionDB(db))
+	}
+	if *verbose {
+		logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		opts = append(opts, solver.WithLogger(logger))
+	}
+
+	color, err := useColor(*colorMode)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if color {
+		opts = append(opts, solver.WithColor(true))
+	}
+
+	// -all prints solutions while searching, and the redrawn status line would
+	// end up between them
+	if *progress && !*all && isTerminal(os.Stderr) {
+		opts = append(opts, solver.WithProgress(solver.DefaultProgressInterval, printProgress))
+	}
+
+	var s *solver.CalendarBoardSolver
+	switch *board {
+	case "classic":
+		s = solver.NewCalendarBoardSolver(opts...)
+	case "weekday":
+		s = solver.NewWeekdayBoardSolver(opts...)
+	default:
+		layout, err := solver.LoadBoard(*board)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		s = solver.NewBoardSolver(layout, opts...)
+	}
+	if *pieces != "" {
+		set, err := solver.LoadPieces(*pieces)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if err := s.SetPieces(set); err != nil {
+			log.Fatalf("Error: %s: %v", *pieces, err)
+		}
+	}
+
+	target, err := commandLineTarget(s, blocks, *day, *month, *year)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	// Images only hold the solution, without the text report
+	var write imageWriter
+	render := solver.RenderOptions{CellSize: *cellSize, Delay: *delay}
+	if *palette != "" {
+		render.Palette = strings.Split(*palette, ",")
+	}
+	if *format != "text" || *out != "" {
+		if *all || *count || *testOnly {
+			log.Fatalf("Error: -format and -out cannot be combined with -all, -count or -test-only")
+		}
+		write, err = imageFormat(s, *format, *out)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	if *format != "text" {
+		writeImage(ctx, s, target, write, *out, render)
+		return
+	}
 
 	// Print board configuration
 	s.PrintBoardConfiguration()
@@ -55,35 +431,40 @@ func main() {
 
 	fmt.Println("\n" + strings.Repeat("=", 50))
 
-	// Determine target date
-	var currentDay int
-	var currentMonth string
+	if target.blocked != nil {
+		fmt.Printf("Command line blocked cells: %s\n", target.blocked.String())
+	} else if *day != -1 && *month != "" {
+		fmt.Printf("Command line date: %s\n", target.label(s))
+	} else if !*testOnly {
+		fmt.Printf("Using current date: %s\n", target.label(s))
+	}
 
-	if *day != -1 && *month != "" {
-		currentDay = *day
-		var err error
-		currentMonth, err = getMonthName(*month, s.Months)
-		if err != nil {
-			log.Fatalf("Error: %v\nAvailable months: %s", err, strings.Join(s.Months, ", "))
+	// Enumerate or count every solution instead of stopping at the first one
+	if *all || *count {
+		if *testOnly {
+			log.Fatalf("Error: -all and -count cannot be combined with -test-only")
 		}
-		fmt.Printf("Command line date: %d %s\n", currentDay, currentMonth)
-	} else if !*testOnly {
-		// Use current date
-		now := time.Now()
-		currentDay = now.Day()
-		currentMonth = s.Months[now.Month()-1]
-		fmt.Printf("Using current date: %d %s\n", currentDay, currentMonth)
+		if *count {
+			countSolutions(ctx, s, target)
+			return
+		}
+		enumerateSolutions(ctx, s, target)
+		return
 	}
 
 	// Solve for main date (unless test-only mode)
 	var mainSolveTime time.Duration
 	var mainSolutionFound bool
 	var mainAttempts int64
+	interrupted := false
 
-	if !*testOnly && currentDay != 0 {
-		fmt.Printf("\nSolving calendar board for: %d %s\n", currentDay, currentMonth)
+	if !*testOnly && (target.blocked != nil || !target.date.IsZero()) {
+		label := target.label(s)
+		fmt.Printf("\nSolving calendar board for: %s\n", label)
 		fmt.Printf("Available pieces: %d pieces\n", len(s.Pieces))
 		fmt.Printf("Available CPU cores: %d\n", runtime.NumCPU())
+		fmt.Printf("Search workers: %d\n", s.WorkerCount())
+		fmt.Printf("Search strategy: %s\n", s.Strategy)
 		fmt.Print("Piece sizes: [")
 		for i, piece := range s.Pieces {
 			if i > 0 {
@@ -93,22 +474,44 @@ func main() {
 		}
 		fmt.Println("] cells each")
 
-		// Solve for target date
-		result := s.SolveParallel(currentDay, currentMonth)
+		// Solve for target date or blocked cells
+		result, err := solveWithTimeout(ctx, s, target)
 
-		if result.Found {
+		if errors.Is(err, context.Canceled) {
+			fmt.Printf("\n✗ Interrupted after %.4f seconds\n", result.SolveTime.Seconds())
+			fmt.Printf("Total attempts: %d\n", result.Attempts)
+			interrupted = true
+		} else if errors.Is(err, context.DeadlineExceeded) {
+			fmt.Printf("\n✗ Timed out after %.4f seconds for %s\n", result.SolveTime.Seconds(), label)
+			fmt.Printf("Total attempts: %d\n", result.Attempts)
+		} else if err != nil {
+			log.Fatalf("Error: %v", err)
+		} else if result.Found && result.Precomputed {
+			fmt.Printf("\n✓ Solution taken from the solution database in %.4f seconds\n", result.SolveTime.Seconds())
+			visualize(s, target, result)
+		} else if result.Found {
 			fmt.Printf("\n✓ Solution found in %.4f seconds!\n", result.SolveTime.Seconds())
 			fmt.Printf("Worker %d found the solution after %d attempts\n", result.WorkerID, result.Attempts)
+			fmt.Printf("Nodes searched per worker: %v\n", result.WorkerNodes)
+			fmt.Printf("Pruned placements: %d\n", result.Pruned)
 			if result.SolveTime.Seconds() > 0 {
 				fmt.Printf("Attempts per second: %.0f\n", float64(result.Attempts)/result.SolveTime.Seconds())
 			}
-			s.VisualizeSolution(currentDay, currentMonth, result.Solution, result.PieceMap)
+			visualize(s, target, result)
 		} else {
-			fmt.Printf("\n✗ No solution found for %d %s (took %.4f seconds)\n", currentDay, currentMonth, result.SolveTime.Seconds())
+			fmt.Printf("\n✗ No solution found for %s (took %.4f seconds)\n", label, result.SolveTime.Seconds())
 			fmt.Printf("Total attempts: %d\n", result.Attempts)
 			fmt.Println("This might require adjustment of pieces or board layout.")
 		}
 
+		if write != nil && result.Found {
+			render.Title = label
+			if err := saveImage(*out, write, result, render); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			fmt.Printf("\nImage written to %s\n", *out)
+		}
+
 		mainSolveTime = result.SolveTime
 		mainSolutionFound = result.Found
 		mainAttempts = result.Attempts
@@ -117,40 +520,60 @@ func main() {
 	// Test different dates (only if no specific date was provided)
 	var totalTestTime time.Duration
 	successfulSolves := 0
-	var testDates [][2]interface{}
+	testedDates := 0
 
 	// Skip testing other dates if a specific date was provided via command line
-	if *day == -1 || *month == "" {
+	if interrupted {
+		fmt.Println("\n" + strings.Repeat("=", 50))
+		fmt.Println("Skipping test dates since the solve was interrupted")
+	} else if target.blocked == nil && (*day == -1 || *month == "") {
 		fmt.Println("\n" + strings.Repeat("=", 50))
 		fmt.Println("TESTING OTHER DATES:")
 
-		testDates = [][2]interface{}{
-			{1, "Янв"},
-			{15, "Март"},
-			{31, "Дек"},
-			{29, "Фев"},
+		testDates := []struct {
+			day   int
+			month time.Month
+		}{
+			{1, time.January},
+			{15, time.March},
+			{31, time.December},
+			{29, time.February},
 		}
 
 		for _, testDate := range testDates {
-			day := testDate[0].(int)
-			month := testDate[1].(string)
+			date, err := targetDate(s, *year, testDate.day, testDate.month)
+			if err != nil {
+				// 29 February only exists in leap years on the weekday board
+				fmt.Printf("\nSkipping %d %s: %v\n", testDate.day, s.Months[testDate.month-1], err)
+				continue
+			}
+			label := s.FormatDate(date)
 
-			fmt.Printf("\nTesting %d %s...\n", day, month)
-			result := s.SolveParallel(day, month)
+			fmt.Printf("\nTesting %s...\n", label)
+			result, err := solveWithTimeout(ctx, s, puzzle{date: date})
 			totalTestTime += result.SolveTime
 
-			if result.Found {
-				fmt.Printf("✓ Solution exists for %d %s (solved in %.4fs, %d attempts)\n",
-					day, month, result.SolveTime.Seconds(), result.Attempts)
+			if errors.Is(err, context.Canceled) {
+				fmt.Printf("✗ Interrupted while testing %s (after %.4fs, %d attempts)\n",
+					label, result.SolveTime.Seconds(), result.Attempts)
+				break
+			}
+			testedDates++
+
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				fmt.Printf("✗ Cannot solve %s: %v\n", label, err)
+			} else if result.Found {
+				fmt.Printf("✓ Solution exists for %s (solved in %.4fs, %d attempts)\n",
+					label, result.SolveTime.Seconds(), result.Attempts)
 				successfulSolves++
 			} else {
-				fmt.Printf("✗ No solution for %d %s (took %.4fs, %d attempts)\n",
-					day, month, result.SolveTime.Seconds(), result.Attempts)
+				fmt.Printf("✗ No solution for %s (took %.4fs, %d attempts)\n",
+					label, result.SolveTime.Seconds(), result.Attempts)
 			}
 		}
 	} else {
 		fmt.Println("\n" + strings.Repeat("=", 50))
-		fmt.Printf("Skipping test dates since specific date was provided: %d %s\n", currentDay, currentMonth)
+		fmt.Printf("Skipping test dates since specific date was provided: %s\n", target.label(s))
 	}
 
 	fmt.Println("\n" + strings.Repeat("=", 50))
@@ -162,7 +585,7 @@ func main() {
 	fmt.Printf("- Test cases time: %.4f seconds\n", totalTestTime.Seconds())
 	fmt.Printf("- Total execution time: %.4f seconds\n", (mainSolveTime + totalTestTime).Seconds())
 
-	totalCases := len(testDates)
+	totalCases := testedDates
 	totalSuccessful := successfulSolves
 	if !*testOnly {
 		totalCases++
@@ -177,5 +600,5 @@ func main() {
 		avgTime := (mainSolveTime + totalTestTime).Seconds() / float64(totalCases)
 		fmt.Printf("- Average solve time: %.4f seconds\n", avgTime)
 	}
-	fmt.Printf("- Used %d CPU cores for parallel processing\n", runtime.NumCPU())
+	fmt.Printf("- Used %d workers for parallel processing\n", s.WorkerCount())
 }
@@ -3,41 +3,11 @@ package main
 import (
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 )
 
-func TestGetMonthName(t *testing.T) {
-	months := []string{"Янв", "Фев", "Март", "Апр", "Май", "Июнь", "Июль", "Авг", "Сен", "Окт", "Ноя", "Дек"}
-
-	testCases := []struct {
-		input    string
-		expected string
-		hasError bool
-	}{
-		{"Янв", "Янв", false},
-		{"1", "Янв", false},
-		{"12", "Дек", false},
-		{"янв", "Янв", false},
-		{"март", "Март", false},
-		{"invalid", "", true},
-		{"13", "", true},
-		{"", "", true},
-	}
-
-	for _, tc := range testCases {
-		t.Run(tc.input, func(t *testing.T) {
-			actual, err := getMonthName(tc.input, months)
-			if (err != nil) != tc.hasError {
-				t.Errorf("getMonthName(%q): expected error %v, got %v", tc.input, tc.hasError, err)
-			}
-			if actual != tc.expected {
-				t.Errorf("getMonthName(%q): expected %q, got %q", tc.input, tc.expected, actual)
-			}
-		})
-	}
-}
-
 func TestMainCLI(t *testing.T) {
 	// Build the CLI binary
 	cmd := exec.Command("go", "build", "-o", "../test_calendar_solver_cli", ".")
@@ -48,10 +18,10 @@ func TestMainCLI(t *testing.T) {
 	defer os.Remove("../test_calendar_solver_cli")
 
 	testCases := []struct {
-		name          string
-		args          []string
-		expectedOut   string
-		expectErr     bool
+		name           string
+		args           []string
+		expectedOut    string
+		expectErr      bool
 		notExpectedOut string
 	}{
 		{
@@ -60,9 +30,9 @@ func TestMainCLI(t *testing.T) {
 			expectedOut: "Command line date: 1 Янв",
 		},
 		{
-			name:        "Test Only",
-			args:        []string{"--test-only"},
-			expectedOut: "TESTING OTHER DATES:",
+			name:           "Test Only",
+			args:           []string{"--test-only"},
+			expectedOut:    "TESTING OTHER DATES:",
 			notExpectedOut: "Solving calendar board for:",
 		},
 		{
@@ -71,6 +41,120 @@ func TestMainCLI(t *testing.T) {
 			expectedOut: "Error: invalid month: invalid",
 			expectErr:   true,
 		},
+		{
+			name:        "Weekday Board",
+			args:        []string{"--board", "weekday", "--day", "3", "--month", "4", "--year", "2026"},
+			expectedOut: "Command line date: 3 Апр Пт",
+		},
+		{
+			name:        "Missing Board File",
+			args:        []string{"--board", "no-such-board.txt", "--day", "1", "--month", "1"},
+			expectedOut: "Error: open no-such-board.txt",
+			expectErr:   true,
+		},
+		{
+			name:        "Pieces Do Not Fit",
+			args:        []string{"--pieces", "../solver/pieces/weekday.txt", "--day", "1", "--month", "1"},
+			expectedOut: "piece cells (47) != target positions (41)",
+			expectErr:   true,
+		},
+		{
+			name:        "English Locale",
+			args:        []string{"--locale", "en", "--day", "3", "--month", "Апр"},
+			expectedOut: "Command line date: 3 Apr",
+		},
+		{
+			name:        "Ambiguous Month",
+			args:        []string{"--day", "3", "--month", "Ма"},
+			expectedOut: "Error: ambiguous month: Ма matches Март, Май",
+			expectErr:   true,
+		},
+		{
+			name:        "Placements",
+			args:        []string{"--day", "1", "--month", "1", "--deterministic"},
+			expectedOut: "Piece 1: L-shape at ",
+		},
+		{
+			name:           "SVG Output",
+			args:           []string{"--day", "3", "--month", "4", "--format", "svg"},
+			expectedOut:    `font-weight="bold" text-anchor="middle" fill="#111827">3 Апр</text>`,
+			notExpectedOut: "BOARD CONFIGURATION",
+		},
+		{
+			name:        "Unknown Format",
+			args:        []string{"--day", "3", "--month", "4", "--format", "bmp"},
+			expectedOut: "Error: invalid format: bmp",
+			expectErr:   true,
+		},
+		{
+			name:           "PNG Output",
+			args:           []string{"--day", "3", "--month", "4", "--format", "png", "--cell-size", "30"},
+			expectedOut:    "\x89PNG",
+			notExpectedOut: "BOARD CONFIGURATION",
+		},
+		{
+			name:        "Image File",
+			args:        []string{"--day", "3", "--month", "4", "--out", filepath.Join(t.TempDir(), "solution.png")},
+			expectedOut: "Image written to ",
+		},
+		{
+			name:           "GIF Output",
+			args:           []string{"--day", "3", "--month", "4", "--format", "gif", "--delay", "1s"},
+			expectedOut:    "GIF89a",
+			notExpectedOut: "BOARD CONFIGURATION",
+		},
+		{
+			name:        "Unknown Image Extension",
+			args:        []string{"--day", "3", "--month", "4", "--out", "solution.bmp"},
+			expectedOut: "Error: invalid format: bmp",
+			expectErr:   true,
+		},
+		{
+			name:        "Invalid Palette",
+			args:        []string{"--day", "3", "--month", "4", "--format", "png", "--palette", "#ff0000,blue"},
+			expectedOut: `Error: invalid palette: color "blue" is not #rrggbb`,
+			expectErr:   true,
+		},
+		{
+			name:        "Color Output",
+			args:        []string{"--day", "3", "--month", "4", "--color", "always"},
+			expectedOut: "\x1b[48;2;253;230;138m\x1b[38;2;17;24;39m\x1b[1m Апр  \x1b[0m",
+		},
+		{
+			name:           "Plain Output When Piped",
+			args:           []string{"--day", "3", "--month", "4"},
+			expectedOut:    "X = Current date (3 Апр)",
+			notExpectedOut: "\x1b[48;2",
+		},
+		{
+			name:        "Invalid Color Mode",
+			args:        []string{"--color", "sometimes"},
+			expectedOut: "Error: invalid color mode: sometimes",
+			expectErr:   true,
+		},
+		{
+			name:        "Blocked Cells",
+			args:        []string{"--block", "0,0", "--block", "2,0"},
+			expectedOut: "Solution with blocked cells 0,0 2,0",
+		},
+		{
+			name:        "Blocked Hole",
+			args:        []string{"--block", "0,6", "--block", "2,0"},
+			expectedOut: "Error: invalid cell: 0,6 is not on the board",
+			expectErr:   true,
+		},
+		{
+			name:        "Malformed Block",
+			args:        []string{"--block", "3"},
+			expectedOut: "invalid cell \"3\", expected row,col",
+			expectErr:   true,
+		},
+		{
+			name:        "Invalid Date",
+			args:        []string{"--day", "30", "--month", "2"},
+			expectedOut: "Error: invalid date: 30 Фев",
+			expectErr:   true,
+		},
 		{
 			name:        "No args",
 			args:        []string{},
@@ -97,4 +181,4 @@ func TestMainCLI(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,60 @@
+// Command gendb solves every date of the year and writes the solution
+// database that the solver package embeds.
+//
+//	go run ./gendb -out solver/solutions.db
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"puzzle_solver/solver"
+)
+
+func main() {
+	var out = flag.String("out", "solver/solutions.db", "File to write the database to")
+	var perDate = flag.Int("per-date", 8, "Solutions to store for each date")
+	var strategy = flag.String("strategy", "cell", "Search strategy (backtrack, cell or dlx)")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	searchStrategy, err := solver.ParseStrategy(*strategy)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *perDate < 1 {
+		log.Fatalf("Error: invalid per-date count: %d", *perDate)
+	}
+
+	s := solver.NewCalendarBoardSolver(solver.WithStrategy(searchStrategy), solver.WithTimeout(0))
+
+	start := time.Now()
+	db, err := s.BuildSolutionDB(ctx, *perDate, func(month, day int, count int64) {
+		fmt.Printf("%2d %-5s %5d solutions\n", day, s.Months[month-1], count)
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	size, err := db.WriteTo(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("\nWrote %d dates (%d bytes) to %s in %.1f seconds\n", db.Len(), size, *out, time.Since(start).Seconds())
+	fmt.Printf("Fingerprint: %016x\n", db.Fingerprint)
+}
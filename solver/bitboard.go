@@ -0,0 +1,89 @@
+package solver
+
+import "math/bits"
+
+// The board fits in a uint64: bit row*Cols+col is set when the cell is
+// occupied (or part of a mask). Boards may therefore have at most 64 cells.
+const maxBoardCells = 64
+
+// orientationMask is a single piece orientation anchored at the top-left
+// corner of the board. Shifting the mask by row*Cols+col places the
+// orientation with its bounding box starting at (row, col).
+type orientationMask struct {
+	mask   uint64
+	height int
+	width  int
+}
+
+func (s *CalendarBoardSolver) cellBit(pos Position) uint64 {
+	return 1 << (pos.Row*s.Cols + pos.Col)
+}
+
+func (s *CalendarBoardSolver) positionsFromMask(mask uint64) []Position {
+	positions := make([]Position, 0, bits.OnesCount64(mask))
+	for mask != 0 {
+		index := bits.TrailingZeros64(mask)
+		positions = append(positions, Position{index / s.Cols, index % s.Cols})
+		mask &= mask - 1
+	}
+	return positions
+}
+
+func (s *CalendarBoardSolver) orientationMasks(piece Piece) []orientationMask {
+	orientations := s.getAllOrientations(piece)
+	masks := make([]orientationMask, 0, len(orientations))
+	for _, orientation := range orientations {
+		om := orientationMask{}
+		for _, pos := range orientation {
+			om.mask |= s.cellBit(pos)
+			if pos.Row+1 > om.height {
+				om.height = pos.Row + 1
+			}
+			if pos.Col+1 > om.width {
+				om.width = pos.Col + 1
+			}
+		}
+		masks = append(masks, om)
+	}
+	return masks
+}
+
+// calendarMask returns the bitmask of every labelled cell on the board.
+func (s *CalendarBoardSolver) calendarMask() uint64 {
+	var mask uint64
+	for _, pos := range s.MonthPositions {
+		mask |= s.cellBit(pos)
+	}
+	for _, pos := range s.DayPositions {
+		mask |= s.cellBit(pos)
+	}
+	for _, pos := range s.WeekdayPositions {
+		mask |= s.cellBit(pos)
+	}
+	for _, pos := range s.FixedCells {
+		mask |= s.cellBit(pos)
+	}
+	return mask
+}
+
+// columnMasks returns the cells in the first and last column, which stop
+// horizontal shifts from wrapping around to the neighbouring row.
+func (s *CalendarBoardSolver) columnMasks() (first, last uint64) {
+	for row := 0; row < s.Rows; row++ {
+		first |= s.cellBit(Position{row, 0})
+		last |= s.cellBit(Position{row, s.Cols - 1})
+	}
+	return first, last
+}
+
+// pieceMapFromMasks converts per-piece cell masks into the public
+// position -> piece number representation.
+func (s *CalendarBoardSolver) pieceMapFromMasks(pieceMasks []uint64) map[Position]int {
+	pieceMap := make(map[Position]int)
+	for i, mask := range pieceMasks {
+		for _, pos := range s.positionsFromMask(mask) {
+			pieceMap[pos] = i + 1
+		}
+	}
+	return pieceMap
+}
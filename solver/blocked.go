@@ -0,0 +1,69 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// blockedMask converts explicitly blocked cells into a bitmask. Every cell
+// must be a playable cell of the board.
+func (s *CalendarBoardSolver) blockedMask(cells []Position) (uint64, error) {
+	calendar := s.calendarMask()
+	var blocked uint64
+	for _, cell := range cells {
+		if cell.Row < 0 || cell.Row >= s.Rows || cell.Col < 0 || cell.Col >= s.Cols || s.cellBit(cell)&calendar == 0 {
+			return 0, &InvalidCellError{Cell: cell}
+		}
+		blocked |= s.cellBit(cell)
+	}
+	return blocked, nil
+}
+
+// blockedPuzzle describes a puzzle that leaves the given cells uncovered. The
+// slice is never nil, so that blockedCells tells it apart from a date.
+func blockedPuzzle(cells []Position) calendarDate {
+	return calendarDate{weekday: noWeekday, blocked: append([]Position{}, cells...)}
+}
+
+// SolveBlocked finds a single solution that covers every playable cell except
+// the blocked ones, such as a birthday, two dates or decorative holes. It
+// gives up after the configured Timeout.
+func (s *CalendarBoardSolver) SolveBlocked(blocked []Position) SolveResult {
+	ctx, cancel := s.timeoutContext(context.Background())
+	defer cancel()
+
+	result, err := s.SolveBlockedContext(ctx, blocked)
+	if err != nil && ctx.Err() == nil {
+		s.logger().Warn("solve failed", "blocked", formatCells(blocked), "error", err)
+	}
+	return result
+}
+
+// SolveBlockedContext is like SolveContext for explicitly blocked cells. A
+// cell off the board returns an *InvalidCellError, and a *PieceAreaError is
+// returned when the pieces do not match the remaining cells.
+func (s *CalendarBoardSolver) SolveBlockedContext(ctx context.Context, blocked []Position) (SolveResult, error) {
+	return s.solveFirst(ctx, blockedPuzzle(blocked))
+}
+
+// SolveAllStreamBlockedContext is like SolveAllStreamContext for explicitly
+// blocked cells.
+func (s *CalendarBoardSolver) SolveAllStreamBlockedContext(ctx context.Context, blocked []Position, out chan<- SolveResult) (SolveAllResult, error) {
+	return s.solveAllStream(ctx, blockedPuzzle(blocked), out)
+}
+
+// CountSolutionsBlockedContext is like CountSolutionsContext for explicitly
+// blocked cells.
+func (s *CalendarBoardSolver) CountSolutionsBlockedContext(ctx context.Context, blocked []Position) (CountResult, error) {
+	return s.countSolutions(ctx, blockedPuzzle(blocked))
+}
+
+// formatCells lists cells as "row,col" pairs, e.g. "0,1 3,4".
+func formatCells(cells []Position) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		parts[i] = fmt.Sprintf("%d,%d", cell.Row, cell.Col)
+	}
+	return strings.Join(parts, " ")
+}
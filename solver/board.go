@@ -0,0 +1,173 @@
+package solver
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Board is the layout of a calendar board: its size and the label carried by
+// every playable cell. Boards are read from plain-text grids, see ParseBoard.
+type Board struct {
+	Rows, Cols int
+	Months     map[time.Month]Position
+	Days       map[int]Position
+	Weekdays   map[time.Weekday]Position // Empty on boards without weekdays
+	Fixed      []Position                // Playable cells without a label
+}
+
+//go:embed boards/*.txt
+var boardFiles embed.FS
+
+// builtinBoard parses one of the board files shipped with the package.
+func builtinBoard(name string) *Board {
+	f, err := boardFiles.Open("boards/" + name + ".txt")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	board, err := ParseBoard(f)
+	if err != nil {
+		panic(fmt.Sprintf("built-in board %s: %v", name, err))
+	}
+	return board
+}
+
+// LoadBoard reads a board definition file, see ParseBoard.
+func LoadBoard(path string) (*Board, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseBoard(f)
+}
+
+// ParseBoard reads a board drawn as a grid of space-separated cells, one line
+// per row. M1-M12 are months, D1-D31 days, W0-W6 weekdays starting on Sunday,
+// * is a playable cell without a label and . a hole. Blank lines and lines
+// starting with # are ignored. Every month and day must appear exactly once;
+// weekdays are optional but must be complete if present.
+func ParseBoard(r io.Reader) (*Board, error) {
+	board := &Board{
+		Months:   make(map[time.Month]Position),
+		Days:     make(map[int]Position),
+		Weekdays: make(map[time.Weekday]Position),
+	}
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cells := strings.Fields(line)
+		if board.Rows == 0 {
+			board.Cols = len(cells)
+		} else if len(cells) != board.Cols {
+			return nil, fmt.Errorf("invalid board: line %d has %d cells, expected %d", lineNum, len(cells), board.Cols)
+		}
+
+		for col, cell := range cells {
+			pos := Position{board.Rows, col}
+			if cell == "." {
+				continue
+			}
+			if cell == "*" {
+				board.Fixed = append(board.Fixed, pos)
+				continue
+			}
+			if seen[cell] {
+				return nil, fmt.Errorf("invalid board: line %d: %s appears twice", lineNum, cell)
+			}
+			seen[cell] = true
+
+			// Only the plain spelling of a number is accepted, so that D01 or
+			// M+3 cannot slip past the check for duplicates
+			number, err := strconv.Atoi(cell[1:])
+			switch {
+			case err != nil || strconv.Itoa(number) != cell[1:]:
+				return nil, fmt.Errorf("invalid board: line %d: unknown cell %q", lineNum, cell)
+			case cell[0] == 'M' && number >= 1 && number <= 12:
+				board.Months[time.Month(number)] = pos
+			case cell[0] == 'D' && number >= 1 && number <= 31:
+				board.Days[number] = pos
+			case cell[0] == 'W' && number >= 0 && number <= 6:
+				board.Weekdays[time.Weekday(number)] = pos
+			default:
+				return nil, fmt.Errorf("invalid board: line %d: unknown cell %q", lineNum, cell)
+			}
+		}
+		board.Rows++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if board.Rows*board.Cols > maxBoardCells {
+		return nil, fmt.Errorf("invalid board: %dx%d has more than %d cells", board.Rows, board.Cols, maxBoardCells)
+	}
+	for month := time.January; month <= time.December; month++ {
+		if _, ok := board.Months[month]; !ok {
+			return nil, fmt.Errorf("invalid board: missing month M%d", int(month))
+		}
+	}
+	for day := 1; day <= 31; day++ {
+		if _, ok := board.Days[day]; !ok {
+			return nil, fmt.Errorf("invalid board: missing day D%d", day)
+		}
+	}
+	if len(board.Weekdays) > 0 && len(board.Weekdays) < 7 {
+		return nil, fmt.Errorf("invalid board: %d of 7 weekdays", len(board.Weekdays))
+	}
+	return board, nil
+}
+
+// NewBoardSolver returns a solver for any board layout, labelled in the locale
+// chosen with WithLocale. It starts with the built-in pieces of the classic
+// board, or of the weekday board if the layout has weekday cells; use
+// SetPieces when the board needs a different set.
+func NewBoardSolver(board *Board, opts ...Option) *CalendarBoardSolver {
+	solver := &CalendarBoardSolver{
+		SolverOptions:  defaultOptions(),
+		Rows:           board.Rows,
+		Cols:           board.Cols,
+		MonthPositions: make(map[string]Position),
+		DayPositions:   make(map[int]Position),
+		FixedCells:     append([]Position(nil), board.Fixed...),
+	}
+
+	for _, opt := range opts {
+		opt(&solver.SolverOptions)
+	}
+	locale := solver.locale()
+	solver.Months = append([]string(nil), locale.Months[:]...)
+
+	for month, pos := range board.Months {
+		solver.MonthPositions[solver.Months[month-1]] = pos
+	}
+	for day, pos := range board.Days {
+		solver.DayPositions[day] = pos
+	}
+
+	if len(board.Weekdays) == 0 {
+		solver.usePieces(builtinPieces("classic"))
+		return solver
+	}
+
+	solver.Weekdays = append([]string(nil), locale.Weekdays[:]...)
+	solver.WeekdayPositions = make(map[time.Weekday]Position)
+	for weekday, pos := range board.Weekdays {
+		solver.WeekdayPositions[weekday] = pos
+	}
+	solver.usePieces(builtinPieces("weekday"))
+	return solver
+}
@@ -0,0 +1,93 @@
+package solver
+
+import "math/bits"
+
+// move places a piece at the cells of mask.
+type move struct {
+	piece int
+	mask  uint64
+}
+
+// cellOptionsFor indexes the placements by every cell they cover, so the
+// most-constrained-cell search can look up the candidates for a cell directly.
+func cellOptionsFor(placements [][]placement) [][]move {
+	options := make([][]move, maxBoardCells)
+	for pieceIndex, piecePlacements := range placements {
+		for _, p := range piecePlacements {
+			for mask := p.mask; mask != 0; mask &= mask - 1 {
+				cell := bits.TrailingZeros64(mask)
+				options[cell] = append(options[cell], move{piece: pieceIndex, mask: p.mask})
+			}
+		}
+	}
+	return options
+}
+
+// mostConstrainedCell returns the empty cell with the fewest placements that
+// still fit, preferring the lowest cell on ties. It returns -1 if some empty
+// cell can no longer be covered by any unused piece.
+func (s *CalendarBoardSolver) mostConstrainedCell(state *searchState, work *WorkItem) int {
+	best, bestCount := -1, 0
+	for empty := state.target &^ work.Board; empty != 0; empty &= empty - 1 {
+		cell := bits.TrailingZeros64(empty)
+
+		count := 0
+		for _, option := range state.cellOptions[cell] {
+			if work.PieceMasks[option.piece] == 0 && s.canPlacePiece(work.Board, option.mask) {
+				count++
+				if best != -1 && count >= bestCount {
+					break // Cannot beat the current best
+				}
+			}
+		}
+
+		if count == 0 {
+			return -1 // Dead end: nothing fits here any more
+		}
+		if best == -1 || count < bestCount {
+			best, bestCount = cell, count
+			if count == 1 {
+				break // Forced move
+			}
+		}
+	}
+	return best
+}
+
+// cellSearch fills the board cell by cell: it picks the most constrained empty
+// cell and tries every unused piece orientation that covers it.
+func (s *CalendarBoardSolver) cellSearch(work *WorkItem, state *searchState, workerID int) bool {
+	state.countNode(workerID, work.Depth)
+
+	// Check if we found a solution
+	if work.Board == state.target {
+		return s.reportSolution(state, work, workerID)
+	}
+
+	// Check if we should stop
+	select {
+	case <-state.done:
+		return true
+	default:
+	}
+
+	cell := s.mostConstrainedCell(state, work)
+	if cell == -1 {
+		return false
+	}
+
+	for _, option := range state.cellOptions[cell] {
+		if work.PieceMasks[option.piece] != 0 || !s.canPlacePiece(work.Board, option.mask) {
+			continue
+		}
+
+		s.placePiece(work, option.piece, option.mask)
+		stop := !s.pruneAfterPlacement(state, work) && s.cellSearch(work, state, workerID)
+		s.removePiece(work, option.piece)
+		if stop {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// leapYear is used for dates without a year, so that 29 Фев stays valid: the
+// board has a cell for it and the puzzle is solved for it every fourth year.
+const leapYear = 2024
+
+// daysIn returns the number of days of a month, in a leap year if year is 0.
+func daysIn(month time.Month, year int) int {
+	if year == 0 {
+		year = leapYear
+	}
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// CheckDate returns an *InvalidDateError unless day and month form a real
+// date. With year 0 any year is assumed, so 29 February is accepted; with a
+// year it is only accepted in leap years.
+func (s *CalendarBoardSolver) CheckDate(year int, month time.Month, day int) error {
+	if month < time.January || month > time.December {
+		return &InvalidDateError{Day: day, Month: fmt.Sprint(int(month)), Year: year}
+	}
+	if day < 1 || day > daysIn(month, year) {
+		return &InvalidDateError{Day: day, Month: s.Months[month-1], Year: year}
+	}
+	return nil
+}
+
+// checkDate validates a date given by its board labels.
+func (s *CalendarBoardSolver) checkDate(date calendarDate) error {
+	for i, month := range s.Months {
+		if month == date.month {
+			return s.CheckDate(0, time.Month(i+1), date.day)
+		}
+	}
+	return &InvalidDateError{Day: date.day, Month: date.month}
+}
+
+// SolveMonthDay finds a single solution for a day of a month and gives up
+// after the configured Timeout. 29 February is valid, the board has no year.
+func (s *CalendarBoardSolver) SolveMonthDay(month time.Month, day int) SolveResult {
+	ctx, cancel := s.timeoutContext(context.Background())
+	defer cancel()
+
+	result, err := s.SolveMonthDayContext(ctx, month, day)
+	s.logUnreturnedError(err, day, month.String())
+	return result
+}
+
+// SolveMonthDayContext is like SolveContext for a day of a month. Dates that
+// do not exist return an *InvalidDateError, and weekday boards return
+// ErrWeekdayRequired.
+func (s *CalendarBoardSolver) SolveMonthDayContext(ctx context.Context, month time.Month, day int) (SolveResult, error) {
+	if err := s.CheckDate(0, month, day); err != nil {
+		return SolveResult{}, err
+	}
+	return s.solveFirst(ctx, calendarDate{day: day, month: s.Months[month-1], weekday: noWeekday})
+}
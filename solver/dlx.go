@@ -0,0 +1,185 @@
+package solver
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// dlxMatrix is the exact-cover matrix of a single solve, stored as Knuth's
+// dancing links in flat slices. Node 0 is the root, nodes 1..columns are the
+// column headers and every following node is a 1 in the matrix.
+//
+// There is one column per target cell and one per piece, so a set of rows
+// covering every column exactly once places each piece once and fills every
+// cell once.
+type dlxMatrix struct {
+	left, right, up, down []int
+	column                []int // Column header of each node
+	size                  []int // Number of nodes in each column, indexed by header
+	row                   []int // Row of each node
+
+	rowPiece []int    // Piece index of each row
+	rowMask  []uint64 // Cells covered by each row
+
+	solution []int // Rows chosen on the current search path
+}
+
+func newDLXMatrix(target uint64, placements [][]placement) *dlxMatrix {
+	numCells := bits.OnesCount64(target)
+	numColumns := numCells + len(placements)
+
+	d := &dlxMatrix{}
+	for i := 0; i <= numColumns; i++ {
+		d.left = append(d.left, i-1)
+		d.right = append(d.right, i+1)
+		d.up = append(d.up, i)
+		d.down = append(d.down, i)
+		d.column = append(d.column, i)
+		d.size = append(d.size, 0)
+		d.row = append(d.row, -1)
+	}
+	d.left[0] = numColumns
+	d.right[numColumns] = 0
+
+	// Map every target cell to its column header
+	cellColumn := make(map[int]int, numCells)
+	for mask := target; mask != 0; mask &= mask - 1 {
+		cellColumn[bits.TrailingZeros64(mask)] = len(cellColumn) + 1
+	}
+
+	for pieceIndex, piecePlacements := range placements {
+		pieceColumn := numCells + 1 + pieceIndex
+		for _, p := range piecePlacements {
+			rowIndex := len(d.rowPiece)
+			d.rowPiece = append(d.rowPiece, pieceIndex)
+			d.rowMask = append(d.rowMask, p.mask)
+
+			first := d.appendNode(pieceColumn, rowIndex, -1)
+			for mask := p.mask; mask != 0; mask &= mask - 1 {
+				d.appendNode(cellColumn[bits.TrailingZeros64(mask)], rowIndex, first)
+			}
+		}
+	}
+
+	return d
+}
+
+// appendNode adds a node at the bottom of column c. When first is not -1 the
+// node is linked into the row that starts at first.
+func (d *dlxMatrix) appendNode(c, rowIndex, first int) int {
+	node := len(d.column)
+	d.column = append(d.column, c)
+	d.row = append(d.row, rowIndex)
+
+	d.up = append(d.up, d.up[c])
+	d.down = append(d.down, c)
+	d.down[d.up[c]] = node
+	d.up[c] = node
+	d.size[c]++
+
+	if first == -1 {
+		d.left = append(d.left, node)
+		d.right = append(d.right, node)
+	} else {
+		last := d.left[first]
+		d.left = append(d.left, last)
+		d.right = append(d.right, first)
+		d.right[last] = node
+		d.left[first] = node
+	}
+	return node
+}
+
+func (d *dlxMatrix) cover(c int) {
+	d.right[d.left[c]] = d.right[c]
+	d.left[d.right[c]] = d.left[c]
+	for i := d.down[c]; i != c; i = d.down[i] {
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.down[d.up[j]] = d.down[j]
+			d.up[d.down[j]] = d.up[j]
+			d.size[d.column[j]]--
+		}
+	}
+}
+
+func (d *dlxMatrix) uncover(c int) {
+	for i := d.up[c]; i != c; i = d.up[i] {
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.size[d.column[j]]++
+			d.down[d.up[j]] = j
+			d.up[d.down[j]] = j
+		}
+	}
+	d.right[d.left[c]] = c
+	d.left[d.right[c]] = c
+}
+
+// chooseColumn picks the column with the fewest remaining rows, which is the
+// most constrained cell or piece.
+func (d *dlxMatrix) chooseColumn() int {
+	best := d.right[0]
+	for c := d.right[best]; c != 0; c = d.right[c] {
+		if d.size[c] < d.size[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+func (s *CalendarBoardSolver) dlxWorker(workerID int, state *searchState, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer state.markIdle(workerID)
+
+	d := newDLXMatrix(state.target, state.placements)
+	s.dlxSearch(d, state, workerID)
+}
+
+func (s *CalendarBoardSolver) dlxSearch(d *dlxMatrix, state *searchState, workerID int) bool {
+	state.countNode(workerID, len(d.solution))
+
+	// Every column is covered, so the chosen rows form a solution
+	if d.right[0] == 0 {
+		work := WorkItem{PieceMasks: make([]uint64, len(state.placements)), Depth: len(d.solution)}
+		for _, r := range d.solution {
+			work.PieceMasks[d.rowPiece[r]] = d.rowMask[r]
+			work.Board |= d.rowMask[r]
+			work.placed = append(work.placed, d.rowPiece[r])
+		}
+		return s.reportSolution(state, &work, workerID)
+	}
+
+	// Check if we should stop
+	select {
+	case <-state.done:
+		return true
+	default:
+	}
+
+	c := d.chooseColumn()
+	if d.size[c] == 0 {
+		return false // Some cell or piece can no longer be placed
+	}
+
+	d.cover(c)
+	for i := d.down[c]; i != c; i = d.down[i] {
+		d.solution = append(d.solution, d.row[i])
+		for j := d.right[i]; j != i; j = d.right[j] {
+			d.cover(d.column[j])
+		}
+
+		stop := s.dlxSearch(d, state, workerID)
+
+		for j := d.left[i]; j != i; j = d.left[j] {
+			d.uncover(d.column[j])
+		}
+		d.solution = d.solution[:len(d.solution)-1]
+
+		if stop {
+			d.uncover(c)
+			return true
+		}
+	}
+	d.uncover(c)
+
+	return false
+}
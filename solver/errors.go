@@ -0,0 +1,39 @@
+package solver
+
+import "fmt"
+
+// PieceAreaError is returned when the pieces cannot exactly cover the cells
+// left free by the blocked date.
+type PieceAreaError struct {
+	PieceCells  int // Cells covered by all pieces together
+	TargetCells int // Free calendar cells that must be covered
+}
+
+func (e *PieceAreaError) Error() string {
+	return fmt.Sprintf("piece cells (%d) != target positions (%d)", e.PieceCells, e.TargetCells)
+}
+
+// InvalidDateError is returned for a day and month that do not form a
+// calendar date, such as 31 Фев, day 0 or an unknown month label.
+type InvalidDateError struct {
+	Day   int
+	Month string // Board label of the month, or the month as given if unknown
+	Year  int    // Only set when the date was checked for a specific year
+}
+
+func (e *InvalidDateError) Error() string {
+	if e.Year != 0 {
+		return fmt.Sprintf("invalid date: %d %s %d", e.Day, e.Month, e.Year)
+	}
+	return fmt.Sprintf("invalid date: %d %s", e.Day, e.Month)
+}
+
+// InvalidCellError is returned when a cell passed to SolveBlocked is not a
+// playable cell of the board.
+type InvalidCellError struct {
+	Cell Position
+}
+
+func (e *InvalidCellError) Error() string {
+	return fmt.Sprintf("invalid cell: %d,%d is not on the board", e.Cell.Row, e.Cell.Col)
+}
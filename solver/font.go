@@ -0,0 +1,166 @@
+package solver
+
+// The PNG renderer draws labels with this built-in 5x7 bitmap font, so it
+// needs no font files. Every glyph is seven rows of five pixels, # marking
+// the pixels that are set. Lowercase letters sit in the lower five rows.
+const (
+	glyphWidth   = 5
+	glyphHeight  = 7
+	glyphAdvance = glyphWidth + 1
+)
+
+var font5x7 = map[rune][glyphHeight]string{
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	',': {".....", ".....", ".....", ".....", ".##..", "..#..", ".#..."},
+	':': {".....", ".##..", ".##..", ".....", ".##..", ".##..", "....."},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'+': {".....", "..#..", "..#..", "#####", "..#..", "..#..", "....."},
+	'*': {".....", "..#..", "#.#.#", ".###.", "#.#.#", "..#..", "....."},
+	'/': {"....#", "....#", "...#.", "..#..", ".#...", "#....", "#...."},
+	'(': {"...#.", "..#..", ".#...", ".#...", ".#...", "..#..", "...#."},
+	')': {".#...", "..#..", "...#.", "...#.", "...#.", "..#..", ".#..."},
+	'?': {".###.", "#...#", "....#", "...#.", "..#..", ".....", "..#.."},
+
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+
+	'A': {".###.", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'B': {"####.", "#...#", "#...#", "####.", "#...#", "#...#", "####."},
+	'C': {".###.", "#...#", "#....", "#....", "#....", "#...#", ".###."},
+	'D': {"###..", "#..#.", "#...#", "#...#", "#...#", "#..#.", "###.."},
+	'E': {"#####", "#....", "#....", "####.", "#....", "#....", "#####"},
+	'F': {"#####", "#....", "#....", "####.", "#....", "#....", "#...."},
+	'G': {".###.", "#...#", "#....", "#.###", "#...#", "#...#", ".####"},
+	'H': {"#...#", "#...#", "#...#", "#####", "#...#", "#...#", "#...#"},
+	'I': {".###.", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'J': {"..###", "...#.", "...#.", "...#.", "...#.", "#..#.", ".##.."},
+	'K': {"#...#", "#..#.", "#.#..", "##...", "#.#..", "#..#.", "#...#"},
+	'L': {"#....", "#....", "#....", "#....", "#....", "#....", "#####"},
+	'M': {"#...#", "##.##", "#.#.#", "#.#.#", "#...#", "#...#", "#...#"},
+	'N': {"#...#", "#...#", "##..#", "#.#.#", "#..##", "#...#", "#...#"},
+	'O': {".###.", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'P': {"####.", "#...#", "#...#", "####.", "#....", "#....", "#...."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'R': {"####.", "#...#", "#...#", "####.", "#.#..", "#..#.", "#...#"},
+	'S': {".####", "#....", "#....", ".###.", "....#", "....#", "####."},
+	'T': {"#####", "..#..", "..#..", "..#..", "..#..", "..#..", "..#.."},
+	'U': {"#...#", "#...#", "#...#", "#...#", "#...#", "#...#", ".###."},
+	'V': {"#...#", "#...#", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "#.#.#", ".#.#."},
+	'X': {"#...#", "#...#", ".#.#.", "..#..", ".#.#.", "#...#", "#...#"},
+	'Y': {"#...#", "#...#", ".#.#.", "..#..", "..#..", "..#..", "..#.."},
+	'Z': {"#####", "....#", "...#.", "..#..", ".#...", "#....", "#####"},
+
+	'a': {".....", ".....", ".###.", "....#", ".####", "#...#", ".####"},
+	'b': {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "####."},
+	'c': {".....", ".....", ".###.", "#....", "#....", "#...#", ".###."},
+	'd': {"....#", "....#", ".##.#", "#..##", "#...#", "#...#", ".####"},
+	'e': {".....", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'f': {"..##.", ".#..#", ".#...", "###..", ".#...", ".#...", ".#..."},
+	'g': {".....", ".####", "#...#", "#...#", ".####", "....#", ".###."},
+	'h': {"#....", "#....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'i': {"..#..", ".....", ".##..", "..#..", "..#..", "..#..", ".###."},
+	'j': {"...#.", ".....", "..##.", "...#.", "...#.", "#..#.", ".##.."},
+	'k': {"#....", "#....", "#..#.", "#.#..", "##...", "#.#..", "#..#."},
+	'l': {".##..", "..#..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'm': {".....", ".....", "##.#.", "#.#.#", "#.#.#", "#...#", "#...#"},
+	'n': {".....", ".....", "#.##.", "##..#", "#...#", "#...#", "#...#"},
+	'o': {".....", ".....", ".###.", "#...#", "#...#", "#...#", ".###."},
+	'p': {".....", ".....", "####.", "#...#", "####.", "#....", "#...."},
+	'q': {".....", ".....", ".##.#", "#..##", ".####", "....#", "....#"},
+	'r': {".....", ".....", "#.##.", "##..#", "#....", "#....", "#...."},
+	's': {".....", ".....", ".###.", "#....", ".###.", "....#", "####."},
+	't': {".#...", ".#...", "###..", ".#...", ".#...", ".#..#", "..##."},
+	'u': {".....", ".....", "#...#", "#...#", "#...#", "#..##", ".##.#"},
+	'v': {".....", ".....", "#...#", "#...#", "#...#", ".#.#.", "..#.."},
+	'w': {".....", ".....", "#...#", "#...#", "#.#.#", "#.#.#", ".#.#."},
+	'x': {".....", ".....", "#...#", ".#.#.", "..#..", ".#.#.", "#...#"},
+	'y': {".....", ".....", "#...#", "#...#", ".####", "....#", ".###."},
+	'z': {".....", ".....", "#####", "...#.", "..#..", ".#...", "#####"},
+
+	'Б': {"#####", "#....", "#....", "####.", "#...#", "#...#", "####."},
+	'Г': {"#####", "#....", "#....", "#....", "#....", "#....", "#...."},
+	'Д': {".###.", ".#.#.", ".#.#.", ".#.#.", ".#.#.", "#####", "#...#"},
+	'Ё': {".#.#.", ".....", "#####", "#....", "####.", "#....", "#####"},
+	'Ж': {"#.#.#", "#.#.#", ".###.", "..#..", ".###.", "#.#.#", "#.#.#"},
+	'З': {".###.", "#...#", "....#", "..##.", "....#", "#...#", ".###."},
+	'И': {"#...#", "#...#", "#..##", "#.#.#", "##..#", "#...#", "#...#"},
+	'Й': {".#.#.", "..#..", "#...#", "#..##", "#.#.#", "##..#", "#...#"},
+	'Л': {"..###", ".#..#", ".#..#", ".#..#", ".#..#", ".#..#", "#...#"},
+	'П': {"#####", "#...#", "#...#", "#...#", "#...#", "#...#", "#...#"},
+	'У': {"#...#", "#...#", "#...#", ".####", "....#", "#...#", ".###."},
+	'Ф': {"..#..", ".###.", "#.#.#", "#.#.#", "#.#.#", ".###.", "..#.."},
+	'Ц': {"#..#.", "#..#.", "#..#.", "#..#.", "#..#.", "#####", "....#"},
+	'Ч': {"#...#", "#...#", "#...#", ".####", "....#", "....#", "....#"},
+	'Ш': {"#.#.#", "#.#.#", "#.#.#", "#.#.#", "#.#.#", "#.#.#", "#####"},
+	'Щ': {"#.#.#", "#.#.#", "#.#.#", "#.#.#", "#.#.#", "#####", "....#"},
+	'Ъ': {"##...", ".#...", ".#...", ".###.", ".#..#", ".#..#", ".###."},
+	'Ы': {"#...#", "#...#", "#...#", "##..#", "#.#.#", "#.#.#", "##..#"},
+	'Ь': {"#....", "#....", "#....", "####.", "#...#", "#...#", "####."},
+	'Э': {".###.", "#...#", "....#", "..###", "....#", "#...#", ".###."},
+	'Ю': {"#..#.", "#.#.#", "#.#.#", "###.#", "#.#.#", "#.#.#", "#..#."},
+	'Я': {".####", "#...#", "#...#", ".####", "..#.#", ".#..#", "#...#"},
+
+	'б': {"..###", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'в': {".....", ".....", "####.", "#...#", "####.", "#...#", "####."},
+	'г': {".....", ".....", "#####", "#....", "#....", "#....", "#...."},
+	'д': {".....", ".....", ".###.", ".#.#.", ".#.#.", "#####", "#...#"},
+	'ё': {".#.#.", ".....", ".###.", "#...#", "#####", "#....", ".###."},
+	'ж': {".....", ".....", "#.#.#", ".###.", "..#..", ".###.", "#.#.#"},
+	'з': {".....", ".....", "####.", "....#", "..##.", "....#", "####."},
+	'и': {".....", ".....", "#...#", "#..##", "#.#.#", "##..#", "#...#"},
+	'й': {".....", "..#..", "#...#", "#..##", "#.#.#", "##..#", "#...#"},
+	'к': {".....", ".....", "#..#.", "#.#..", "##...", "#.#..", "#..#."},
+	'л': {".....", ".....", "..###", ".#..#", ".#..#", ".#..#", "#...#"},
+	'м': {".....", ".....", "#...#", "##.##", "#.#.#", "#...#", "#...#"},
+	'н': {".....", ".....", "#...#", "#...#", "#####", "#...#", "#...#"},
+	'п': {".....", ".....", "#####", "#...#", "#...#", "#...#", "#...#"},
+	'т': {".....", ".....", "#####", "..#..", "..#..", "..#..", "..#.."},
+	'ф': {".....", "..#..", ".###.", "#.#.#", "#.#.#", ".###.", "..#.."},
+	'ц': {".....", ".....", "#..#.", "#..#.", "#..#.", "#####", "....#"},
+	'ч': {".....", ".....", "#...#", "#...#", ".####", "....#", "....#"},
+	'ш': {".....", ".....", "#.#.#", "#.#.#", "#.#.#", "#.#.#", "#####"},
+	'щ': {".....", ".....", "#.#.#", "#.#.#", "#.#.#", "#####", "....#"},
+	'ъ': {".....", ".....", "##...", ".#...", ".###.", ".#..#", ".###."},
+	'ы': {".....", ".....", "#...#", "#...#", "##..#", "#.#.#", "##..#"},
+	'ь': {".....", ".....", "#....", "#....", "####.", "#...#", "####."},
+	'э': {".....", ".....", ".###.", "#...#", "..###", "#...#", ".###."},
+	'ю': {".....", ".....", "#..#.", "#.#.#", "###.#", "#.#.#", "#..#."},
+	'я': {".....", ".....", ".####", "#...#", ".####", ".#..#", "#...#"},
+}
+
+// fontAliases reuses the Latin glyphs for Cyrillic letters that look the same.
+var fontAliases = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O',
+	'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X',
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x',
+}
+
+// glyph returns the bitmap of a rune, or of '?' if the font lacks it.
+func glyph(r rune) [glyphHeight]string {
+	if alias, ok := fontAliases[r]; ok {
+		r = alias
+	}
+	if g, ok := font5x7[r]; ok {
+		return g
+	}
+	return font5x7['?']
+}
+
+// textWidth returns the width in font pixels of a line of text.
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*glyphAdvance - 1
+}
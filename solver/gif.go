@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"time"
+)
+
+// WriteGIF animates how a solution is put together: the empty board with the
+// free cells marked, then one frame per piece in the order the search placed
+// them, holding the finished board longer. Without a solution it draws the
+// labelled board as a single frame.
+func (s *CalendarBoardSolver) WriteGIF(w io.Writer, result SolveResult, opts RenderOptions) error {
+	colors, err := s.pieceColors(opts)
+	if err != nil {
+		return err
+	}
+
+	var frames []*image.RGBA
+	if !result.Found {
+		frames = append(frames, s.drawBoard(opts, colors, nil, 0))
+	} else {
+		free := s.calendarMask()
+		for pos := range result.PieceMap {
+			free &^= s.cellBit(pos)
+		}
+
+		// Database answers carry no search order, so they go in piece order
+		order := result.Order
+		if len(order) != len(result.Placements) {
+			order = make([]int, len(result.Placements))
+			for i, p := range result.Placements {
+				order[i] = p.Piece
+			}
+		}
+		cells := make(map[int][]Position, len(result.Placements))
+		for _, p := range result.Placements {
+			cells[p.Piece] = p.Cells
+		}
+
+		pieceMap := make(map[Position]int)
+		frames = append(frames, s.drawBoard(opts, colors, pieceMap, free))
+		for _, piece := range order {
+			for _, pos := range cells[piece] {
+				pieceMap[pos] = piece + 1
+			}
+			frames = append(frames, s.drawBoard(opts, colors, pieceMap, free))
+		}
+	}
+
+	delay := int(opts.delay() / (10 * time.Millisecond)) // GIF delays are in 1/100 s
+	anim := &gif.GIF{Image: toPaletted(frames)}
+	for i := range frames {
+		if i == len(frames)-1 {
+			anim.Delay = append(anim.Delay, 4*delay)
+		} else {
+			anim.Delay = append(anim.Delay, delay)
+		}
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// toPaletted converts frames to paletted images sharing one palette. The
+// renderers use few colours, so the palette is normally exact; beyond the 256
+// colours of a GIF the frames are dithered to the Plan 9 palette instead.
+func toPaletted(frames []*image.RGBA) []*image.Paletted {
+	index := make(map[color.RGBA]uint8)
+	var colors color.Palette
+	exact := true
+collect:
+	for _, frame := range frames {
+		for i := 0; i < len(frame.Pix); i += 4 {
+			c := color.RGBA{frame.Pix[i], frame.Pix[i+1], frame.Pix[i+2], frame.Pix[i+3]}
+			if _, seen := index[c]; seen {
+				continue
+			}
+			if len(colors) == 256 {
+				exact = false
+				break collect
+			}
+			index[c] = uint8(len(colors))
+			colors = append(colors, c)
+		}
+	}
+
+	images := make([]*image.Paletted, len(frames))
+	for i, frame := range frames {
+		if !exact {
+			images[i] = image.NewPaletted(frame.Bounds(), palette.Plan9)
+			draw.FloydSteinberg.Draw(images[i], frame.Bounds(), frame, image.Point{})
+			continue
+		}
+		// The frames start at 0,0 without padding, so pixels line up one to one
+		img := image.NewPaletted(frame.Bounds(), colors)
+		for j := 0; j < len(frame.Pix); j += 4 {
+			img.Pix[j/4] = index[color.RGBA{frame.Pix[j], frame.Pix[j+1], frame.Pix[j+2], frame.Pix[j+3]}]
+		}
+		images[i] = img
+	}
+	return images
+}
@@ -0,0 +1,131 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale holds the labels printed on the board in one language, together with
+// the full month names accepted by ParseMonth.
+type Locale struct {
+	Name       string     // Short name such as "ru", see LookupLocale
+	Months     [12]string // Board labels, January first
+	MonthNames [12]string // Full month names, January first
+	Weekdays   [7]string  // Board labels indexed by time.Weekday
+}
+
+var (
+	// LocaleRussian is the default locale, matching the printed puzzle.
+	LocaleRussian = &Locale{
+		Name: "ru",
+		Months: [12]string{
+			"Янв", "Фев", "Март", "Апр", "Май", "Июнь",
+			"Июль", "Авг", "Сент", "Окт", "Нояб", "Дек",
+		},
+		MonthNames: [12]string{
+			"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+			"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь",
+		},
+		Weekdays: [7]string{"Вс", "Пн", "Вт", "Ср", "Чт", "Пт", "Сб"},
+	}
+
+	LocaleEnglish = &Locale{
+		Name: "en",
+		Months: [12]string{
+			"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+			"Jul", "Aug", "Sep", "Oct", "Nov", "Dec",
+		},
+		MonthNames: [12]string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		Weekdays: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	}
+)
+
+var locales = []*Locale{LocaleRussian, LocaleEnglish}
+
+// LocaleNames returns the names of the supported locales.
+func LocaleNames() []string {
+	names := make([]string, len(locales))
+	for i, locale := range locales {
+		names[i] = locale.Name
+	}
+	return names
+}
+
+// LookupLocale returns the locale with the given name, e.g. "en".
+func LookupLocale(name string) (*Locale, error) {
+	for _, locale := range locales {
+		if strings.EqualFold(name, locale.Name) {
+			return locale, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid locale: %s (available: %s)", name, strings.Join(LocaleNames(), ", "))
+}
+
+// AmbiguousMonthError is returned by ParseMonth when the input is the start
+// of several month names, such as "Ма" for Март and Май.
+type AmbiguousMonthError struct {
+	Input   string
+	Matches []string // One name of each matching month, in calendar order
+}
+
+func (e *AmbiguousMonthError) Error() string {
+	return fmt.Sprintf("ambiguous month: %s matches %s", e.Input, strings.Join(e.Matches, ", "))
+}
+
+// ParseMonth converts a month number (1-12), board label or full month name
+// in any supported locale into a time.Month. Case is ignored and any prefix
+// that only fits one month is accepted.
+func ParseMonth(input string) (time.Month, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return 0, fmt.Errorf("invalid month: empty string")
+	}
+	if number, err := strconv.Atoi(input); err == nil {
+		if number < 1 || number > 12 {
+			return 0, fmt.Errorf("invalid month: %s", input)
+		}
+		return time.Month(number), nil
+	}
+
+	// An exact label or name wins over prefix matches
+	lower := strings.ToLower(input)
+	prefixed := make(map[time.Month]string)
+	for _, locale := range locales {
+		for i := range 12 {
+			for _, name := range []string{locale.Months[i], locale.MonthNames[i]} {
+				name = strings.ToLower(name)
+				if name == lower {
+					return time.Month(i + 1), nil
+				}
+				if _, seen := prefixed[time.Month(i+1)]; !seen && strings.HasPrefix(name, lower) {
+					prefixed[time.Month(i+1)] = locale.MonthNames[i]
+				}
+			}
+		}
+	}
+
+	switch len(prefixed) {
+	case 0:
+		return 0, fmt.Errorf("invalid month: %s", input)
+	case 1:
+		for month := range prefixed {
+			return month, nil
+		}
+	}
+	months := make([]time.Month, 0, len(prefixed))
+	for month := range prefixed {
+		months = append(months, month)
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+	matches := make([]string, len(months))
+	for i, month := range months {
+		matches[i] = prefixed[month]
+	}
+	return 0, &AmbiguousMonthError{Input: input, Matches: matches}
+}
@@ -0,0 +1,214 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Strategy selects the search algorithm used by the solver.
+type Strategy int
+
+const (
+	StrategyBacktrack       Strategy = iota // Place pieces in order on parallel workers
+	StrategyDLX                             // Dancing Links exact cover (Algorithm X)
+	StrategyMostConstrained                 // Fill the empty cell with the fewest options first
+)
+
+var strategyNames = map[Strategy]string{
+	StrategyBacktrack:       "backtrack",
+	StrategyDLX:             "dlx",
+	StrategyMostConstrained: "cell",
+}
+
+func (st Strategy) String() string {
+	if name, ok := strategyNames[st]; ok {
+		return name
+	}
+	return fmt.Sprintf("Strategy(%d)", int(st))
+}
+
+// ParseStrategy converts a strategy name such as "dlx" into a Strategy.
+func ParseStrategy(name string) (Strategy, error) {
+	for st, stName := range strategyNames {
+		if strings.EqualFold(name, stName) {
+			return st, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid strategy: %s", name)
+}
+
+// OrientationRule selects which piece orientations may be placed.
+type OrientationRule int
+
+const (
+	OrientationsAll        OrientationRule = iota // Rotations and mirror images
+	OrientationsRotateOnly                        // Rotations only, pieces cannot be flipped
+)
+
+// DefaultTimeout bounds SolveParallel so that dates without a solution do not
+// keep the workers busy forever.
+const DefaultTimeout = 60 * time.Second
+
+// SolverOptions controls how a solver searches. Options must be set before
+// the first solve, because the placement table is built from them once.
+type SolverOptions struct {
+	Workers            int             // Search goroutines, 0 means runtime.NumCPU()
+	Timeout            time.Duration   // Limit for SolveParallel, 0 means no limit
+	Strategy           Strategy        // Search algorithm
+	Orientations       OrientationRule // Allowed piece orientations
+	WorkItemsPerWorker int             // Subtrees queued per worker, 0 means 16
+	DisablePruning     bool            // Skip dead-region pruning after each placement
+	Deterministic      bool            // Return the lexicographically first solution, see WithDeterministic
+	Shuffle            bool            // Try placements in an order shuffled with Seed
+	Seed               uint64          // Seed for Shuffle, see WithRandomSeed
+	SolutionDB         *SolutionDB     // Precomputed answers, see WithSolutionDB
+	Locale             *Locale         // Board labels, nil means LocaleRussian
+	Color              bool            // Print solutions and pieces in colour, see WithColor
+	Logger             *slog.Logger    // Receives solve diagnostics, nil discards them
+	Progress           func(Progress)  // Called periodically during a solve, see WithProgress
+	ProgressInterval   time.Duration   // Time between progress events
+}
+
+// Option configures a CalendarBoardSolver in NewCalendarBoardSolver.
+type Option func(*SolverOptions)
+
+func defaultOptions() SolverOptions {
+	return SolverOptions{
+		Timeout: DefaultTimeout,
+	}
+}
+
+// WithWorkers limits the number of search goroutines. The process-wide
+// GOMAXPROCS setting is left untouched.
+func WithWorkers(n int) Option {
+	return func(o *SolverOptions) {
+		o.Workers = n
+	}
+}
+
+// WithTimeout sets the limit used by SolveParallel; 0 disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *SolverOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithStrategy selects the search algorithm.
+func WithStrategy(strategy Strategy) Option {
+	return func(o *SolverOptions) {
+		o.Strategy = strategy
+	}
+}
+
+// WithOrientations selects which piece orientations may be placed.
+func WithOrientations(rule OrientationRule) Option {
+	return func(o *SolverOptions) {
+		o.Orientations = rule
+	}
+}
+
+// WithWorkItemsPerWorker sets how many subtrees are queued per worker before
+// the search starts.
+func WithWorkItemsPerWorker(n int) Option {
+	return func(o *SolverOptions) {
+		o.WorkItemsPerWorker = n
+	}
+}
+
+// WithPruning turns dead-region pruning on or off. It is on by default and
+// applies to the backtracking and most-constrained-cell strategies.
+func WithPruning(enabled bool) Option {
+	return func(o *SolverOptions) {
+		o.DisablePruning = !enabled
+	}
+}
+
+// WithDeterministic makes the first-solution solves return the solution with
+// the smallest PieceMapKey instead of whichever worker finishes first, so every
+// strategy, number of workers and solution database gives the same answer.
+// Finding it takes a full enumeration, as long as SolveAll.
+func WithDeterministic(enabled bool) Option {
+	return func(o *SolverOptions) {
+		o.Deterministic = enabled
+	}
+}
+
+// WithRandomSeed shuffles the order in which placements are tried, so
+// different seeds lead to different first solutions. Combined with
+// WithDeterministic the seed instead picks one of all the solutions in
+// PieceMapKey order, the same one every time.
+func WithRandomSeed(seed uint64) Option {
+	return func(o *SolverOptions) {
+		o.Shuffle = true
+		o.Seed = seed
+	}
+}
+
+// WithSolutionDB answers SolveParallel, SolveContext and the counting methods
+// from a precomputed database when it holds the date and was built for the
+// same board and pieces. Other dates are solved as usual.
+func WithSolutionDB(db *SolutionDB) Option {
+	return func(o *SolverOptions) {
+		o.SolutionDB = db
+	}
+}
+
+// WithLocale selects the language of the month and weekday labels. Dates
+// are the same in every locale, so a solution database works for all of them.
+func WithLocale(locale *Locale) Option {
+	return func(o *SolverOptions) {
+		o.Locale = locale
+	}
+}
+
+// WithColor prints solutions and pieces with ANSI colours and box-drawing
+// borders between the pieces. Only enable it for terminals that support
+// 24-bit colour; the default is plain text.
+func WithColor(enabled bool) Option {
+	return func(o *SolverOptions) {
+		o.Color = enabled
+	}
+}
+
+// WithLogger sends solve diagnostics to logger. Without it the solver stays
+// silent.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *SolverOptions) {
+		o.Logger = logger
+	}
+}
+
+// WorkerCount returns the number of search goroutines the options ask for.
+func (o SolverOptions) WorkerCount() int {
+	if o.Workers > 0 {
+		return o.Workers
+	}
+	return runtime.NumCPU()
+}
+
+func (o SolverOptions) workItemsPerWorker() int {
+	if o.WorkItemsPerWorker > 0 {
+		return o.WorkItemsPerWorker
+	}
+	return 16
+}
+
+func (o SolverOptions) locale() *Locale {
+	if o.Locale != nil {
+		return o.Locale
+	}
+	return LocaleRussian
+}
+
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+func (o SolverOptions) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return discardLogger
+}
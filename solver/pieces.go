@@ -0,0 +1,259 @@
+package solver
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PieceSet is a list of pieces with their display names and colours, as read
+// from a piece definition file, see ParsePieces.
+type PieceSet struct {
+	Pieces []Piece
+	Names  []string
+	Colors []string // "#rrggbb", empty when the file gives none
+}
+
+// Cells returns the number of cells covered by all pieces together.
+func (set *PieceSet) Cells() int {
+	cells := 0
+	for _, piece := range set.Pieces {
+		cells += len(piece)
+	}
+	return cells
+}
+
+//go:embed pieces/*.txt
+var pieceFiles embed.FS
+
+// builtinPieces parses one of the piece files shipped with the package.
+func builtinPieces(name string) *PieceSet {
+	f, err := pieceFiles.Open("pieces/" + name + ".txt")
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	set, err := ParsePieces(f)
+	if err != nil {
+		panic(fmt.Sprintf("built-in pieces %s: %v", name, err))
+	}
+	return set
+}
+
+// LoadPieces reads a piece definition file, see ParsePieces.
+func LoadPieces(path string) (*PieceSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParsePieces(f)
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ParsePieces reads pieces drawn as ASCII art. Each piece starts with a line
+//
+//	piece <name> [color=#rrggbb] [count=N]
+//
+// followed by its shape, one line per row, with X for cells and . for gaps.
+// count adds N identical copies of the piece. Blank lines and lines starting
+// with # are ignored.
+func ParsePieces(r io.Reader) (*PieceSet, error) {
+	set := &PieceSet{}
+	var name, color string
+	var count int
+	var shape []string
+	headerLine := 0
+
+	// finish adds the piece read so far
+	finish := func() error {
+		if headerLine == 0 {
+			return nil
+		}
+		piece, err := parseShape(shape)
+		if err != nil {
+			return fmt.Errorf("invalid pieces: line %d: %s: %w", headerLine, name, err)
+		}
+		for range count {
+			set.Pieces = append(set.Pieces, piece)
+			set.Names = append(set.Names, name)
+			set.Colors = append(set.Colors, color)
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		// Leading spaces would shift a shape row, so parseShape rejects them
+		row := strings.TrimRightFunc(scanner.Text(), unicode.IsSpace)
+		line := strings.TrimSpace(row)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if fields[0] != "piece" {
+			if headerLine == 0 {
+				return nil, fmt.Errorf("invalid pieces: line %d: shape before the first piece line", lineNum)
+			}
+			shape = append(shape, row)
+			continue
+		}
+
+		if err := finish(); err != nil {
+			return nil, err
+		}
+		var words []string
+		name, color, count, shape, headerLine = "", "", 1, nil, lineNum
+		for _, field := range fields[1:] {
+			key, value, found := strings.Cut(field, "=")
+			switch {
+			case !found:
+				words = append(words, field)
+			case key == "color":
+				if !colorPattern.MatchString(value) {
+					return nil, fmt.Errorf("invalid pieces: line %d: color %q is not #rrggbb", lineNum, value)
+				}
+				color = strings.ToLower(value)
+			case key == "count":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return nil, fmt.Errorf("invalid pieces: line %d: count %q is not a positive number", lineNum, value)
+				}
+				count = n
+			default:
+				return nil, fmt.Errorf("invalid pieces: line %d: unknown attribute %q", lineNum, key)
+			}
+		}
+		name = strings.Join(words, " ")
+		if name == "" {
+			return nil, fmt.Errorf("invalid pieces: line %d: piece without a name", lineNum)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := finish(); err != nil {
+		return nil, err
+	}
+
+	if len(set.Pieces) == 0 {
+		return nil, fmt.Errorf("invalid pieces: no pieces defined")
+	}
+	return set, nil
+}
+
+// parseShape converts the rows of a piece drawing into its cells, shifted so
+// that the piece touches the top and left edges.
+func parseShape(rows []string) (Piece, error) {
+	var piece Piece
+	for row, line := range rows {
+		for col, ch := range []byte(line) {
+			switch ch {
+			case 'X':
+				piece = append(piece, Position{row, col})
+			case '.':
+			default:
+				return nil, fmt.Errorf("unexpected %q in shape", ch)
+			}
+		}
+	}
+	if len(piece) == 0 {
+		return nil, fmt.Errorf("empty shape")
+	}
+	if !isConnected(piece) {
+		return nil, fmt.Errorf("shape is not connected")
+	}
+
+	minRow, minCol := piece[0].Row, piece[0].Col
+	for _, pos := range piece {
+		minRow = min(minRow, pos.Row)
+		minCol = min(minCol, pos.Col)
+	}
+	for i := range piece {
+		piece[i].Row -= minRow
+		piece[i].Col -= minCol
+	}
+	return piece, nil
+}
+
+// isConnected reports whether every cell of a piece can be reached from the
+// first one through edge neighbours.
+func isConnected(piece Piece) bool {
+	cells := make(map[Position]bool, len(piece))
+	for _, pos := range piece {
+		cells[pos] = true
+	}
+	reached := map[Position]bool{piece[0]: true}
+	queue := []Position{piece[0]}
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		for _, next := range []Position{{pos.Row - 1, pos.Col}, {pos.Row + 1, pos.Col}, {pos.Row, pos.Col - 1}, {pos.Row, pos.Col + 1}} {
+			if cells[next] && !reached[next] {
+				reached[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return len(reached) == len(piece)
+}
+
+// SetPieces replaces the solver's pieces. The pieces must cover exactly the
+// calendar cells a date leaves free, otherwise a *PieceAreaError is returned
+// and the solver is left unchanged. Call it before the first solve.
+func (s *CalendarBoardSolver) SetPieces(set *PieceSet) error {
+	target := bits.OnesCount64(s.calendarMask()) - s.blockedPerDate()
+	if cells := set.Cells(); cells != target {
+		return &PieceAreaError{PieceCells: cells, TargetCells: target}
+	}
+	s.usePieces(set)
+	return nil
+}
+
+func (s *CalendarBoardSolver) usePieces(set *PieceSet) {
+	s.Pieces = append([]Piece(nil), set.Pieces...)
+	s.PieceNames = append([]string(nil), set.Names...)
+	s.PieceColors = append([]string(nil), set.Colors...)
+}
+
+// blockedPerDate returns the number of cells every date leaves uncovered.
+func (s *CalendarBoardSolver) blockedPerDate() int {
+	if len(s.WeekdayPositions) > 0 {
+		return 3
+	}
+	return 2
+}
+
+// identicalPieces returns, for every piece, the index of the closest earlier
+// piece with the same shape under the allowed orientations, or -1. Swapping
+// identical pieces gives the same tiling, so only one assignment is kept.
+func (s *CalendarBoardSolver) identicalPieces() []int {
+	previous := make([]int, len(s.Pieces))
+	last := make(map[string]int)
+	for i, piece := range s.Pieces {
+		// Every orientation of a shape yields the same smallest key
+		key := ""
+		for _, orientation := range s.getAllOrientations(piece) {
+			if k := s.pieceToString(orientation); key == "" || k < key {
+				key = k
+			}
+		}
+
+		previous[i] = -1
+		if j, ok := last[key]; ok {
+			previous[i] = j
+		}
+		last[key] = i
+	}
+	return previous
+}
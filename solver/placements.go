@@ -0,0 +1,134 @@
+package solver
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// Transform is how a piece is turned to reach one of its orientations: first
+// flipped over if Flipped, then rotated clockwise by Rotation degrees.
+type Transform struct {
+	Rotation int  // 0, 90, 180 or 270
+	Flipped  bool // Upside down, mirrored top to bottom
+}
+
+// String describes the transform, e.g. "flipped, rotated 90° clockwise".
+func (t Transform) String() string {
+	switch {
+	case t.Flipped && t.Rotation == 0:
+		return "flipped"
+	case t.Flipped:
+		return fmt.Sprintf("flipped, rotated %d° clockwise", t.Rotation)
+	case t.Rotation == 0:
+		return "as drawn"
+	default:
+		return fmt.Sprintf("rotated %d° clockwise", t.Rotation)
+	}
+}
+
+// Placement is one piece of a solution as it lies on the board.
+type Placement struct {
+	Piece       int        // Index into Pieces
+	Orientation int        // Index into the distinct orientations of the piece
+	Transform   Transform  // How the piece is turned for this orientation
+	Anchor      Position   // Top-left corner of the piece's bounding box
+	Cells       []Position // Covered cells, row by row
+}
+
+// placement is one legal position of a piece orientation on the calendar.
+type placement struct {
+	mask        uint64   // Cells covered by the piece
+	orientation int      // Index into getAllOrientations for the piece
+	anchor      Position // Top-left corner of the orientation's bounding box
+}
+
+// placementTable returns every legal (orientation, anchor) placement of each
+// piece on the calendar cells. The table only depends on the board layout and
+// the pieces, so it is built once per solver and shared by all solves.
+func (s *CalendarBoardSolver) placementTable() [][]placement {
+	s.placementsOnce.Do(func() {
+		calendar := s.calendarMask()
+		s.placements = make([][]placement, len(s.Pieces))
+		for i, piece := range s.Pieces {
+			for orientationIndex, orientation := range s.orientationMasks(piece) {
+				for row := 0; row+orientation.height <= s.Rows; row++ {
+					for col := 0; col+orientation.width <= s.Cols; col++ {
+						mask := orientation.mask << (row*s.Cols + col)
+						if mask&^calendar != 0 {
+							continue
+						}
+						s.placements[i] = append(s.placements[i], placement{
+							mask:        mask,
+							orientation: orientationIndex,
+							anchor:      Position{row, col},
+						})
+					}
+				}
+			}
+		}
+	})
+	return s.placements
+}
+
+// placementsFor keeps only the placements that fit on the target cells, which
+// drops everything touching the blocked date before the search starts.
+func (s *CalendarBoardSolver) placementsFor(target uint64) [][]placement {
+	table := s.placementTable()
+	filtered := make([][]placement, len(table))
+	for i, piecePlacements := range table {
+		filtered[i] = make([]placement, 0, len(piecePlacements))
+		for _, p := range piecePlacements {
+			if p.mask&^target == 0 {
+				filtered[i] = append(filtered[i], p)
+			}
+		}
+	}
+	return filtered
+}
+
+// shufflePlacements randomises the order in which each piece's placements are
+// tried. The same seed always gives the same order.
+func shufflePlacements(placements [][]placement, seed uint64) {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for _, piecePlacements := range placements {
+		rng.Shuffle(len(piecePlacements), func(i, j int) {
+			piecePlacements[i], piecePlacements[j] = piecePlacements[j], piecePlacements[i]
+		})
+	}
+}
+
+// shuffleCellOptions randomises the order in which the candidates for each
+// cell are tried, so the most-constrained-cell search does not always start
+// with the lowest piece.
+func shuffleCellOptions(options [][]move, seed uint64) {
+	rng := rand.New(rand.NewPCG(seed, ^seed))
+	for _, cellOptions := range options {
+		rng.Shuffle(len(cellOptions), func(i, j int) {
+			cellOptions[i], cellOptions[j] = cellOptions[j], cellOptions[i]
+		})
+	}
+}
+
+// placementsFromMasks looks up the placement of every piece of a solution in
+// the placement table, in piece order.
+func (s *CalendarBoardSolver) placementsFromMasks(pieceMasks []uint64) []Placement {
+	table := s.placementTable()
+	placements := make([]Placement, 0, len(pieceMasks))
+	for i, mask := range pieceMasks {
+		for _, p := range table[i] {
+			if p.mask != mask {
+				continue
+			}
+			_, transforms := s.orientationsWithTransforms(s.Pieces[i])
+			placements = append(placements, Placement{
+				Piece:       i,
+				Orientation: p.orientation,
+				Transform:   transforms[p.orientation],
+				Anchor:      p.anchor,
+				Cells:       s.positionsFromMask(mask),
+			})
+			break
+		}
+	}
+	return placements
+}
@@ -0,0 +1,156 @@
+package solver
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// RenderImage draws the same picture as WriteSVG into a bitmap, with labels
+// in the built-in bitmap font.
+func (s *CalendarBoardSolver) RenderImage(result SolveResult, opts RenderOptions) (*image.RGBA, error) {
+	colors, err := s.pieceColors(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Found {
+		return s.drawBoard(opts, colors, nil, 0), nil
+	}
+	free := s.calendarMask()
+	for pos := range result.PieceMap {
+		free &^= s.cellBit(pos)
+	}
+	return s.drawBoard(opts, colors, result.PieceMap, free), nil
+}
+
+// drawBoard draws the pieces in pieceMap and highlights the cells in free.
+// The cells in neither stay empty.
+func (s *CalendarBoardSolver) drawBoard(opts RenderOptions, colors []string, pieceMap map[Position]int, free uint64) *image.RGBA {
+	l := s.layout(opts)
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	fillRect(img, img.Bounds(), parseColor(backgroundColor))
+
+	if opts.Title != "" {
+		scale := fitText(opts.Title, max(l.cell*3/10/glyphHeight, 1), l.width-2*l.margin)
+		x := (l.width - textWidth(opts.Title)*scale) / 2
+		y := l.margin + (l.cell/2-glyphHeight*scale)/2
+		drawText(img, opts.Title, x, y, scale, parseColor(labelColor), true)
+	}
+
+	// Board cells, with the cells a solution leaves free highlighted
+	playable := s.positionsFromMask(s.calendarMask())
+	for _, pos := range playable {
+		fill := cellColor
+		if s.cellBit(pos)&free != 0 {
+			fill = blockedColor
+		}
+		x, y := l.corner(point{pos.Col, pos.Row})
+		fillRect(img, image.Rect(x, y, x+l.cell, y+l.cell), parseColor(fill))
+		drawFrame(img, image.Rect(x, y, x+l.cell+1, y+l.cell+1), parseColor(gridColor))
+	}
+
+	// Pieces, then the outline of the whole board
+	stroke := max(l.cell/20, 1)
+	for i, cells := range s.pieceCells(pieceMap) {
+		for _, pos := range cells {
+			x, y := l.corner(point{pos.Col, pos.Row})
+			fillRect(img, image.Rect(x, y, x+l.cell, y+l.cell), parseColor(colors[i]))
+		}
+		drawLoops(img, outline(cells), l, stroke, parseColor(outlineColor))
+	}
+	drawLoops(img, outline(playable), l, stroke*2, parseColor(outlineColor))
+
+	// Labels stay readable on top of the pieces
+	labels := s.cellLabels()
+	for _, pos := range playable {
+		label, ok := labels[pos]
+		if !ok {
+			continue
+		}
+		scale := fitText(label, max(l.cell*7/25/glyphHeight, 1), l.cell-2*stroke)
+		x, y := l.corner(point{pos.Col, pos.Row})
+		x += (l.cell - textWidth(label)*scale) / 2
+		y += (l.cell - glyphHeight*scale) / 2
+		if _, covered := pieceMap[pos]; covered {
+			drawText(img, label, x, y, scale, color.NRGBA{0xff, 0xff, 0xff, 0xbf}, false)
+		} else {
+			drawText(img, label, x, y, scale, parseColor(labelColor), true)
+		}
+	}
+	return img
+}
+
+// WritePNG draws the board like WriteSVG and encodes it as a PNG image.
+func (s *CalendarBoardSolver) WritePNG(w io.Writer, result SolveResult, opts RenderOptions) error {
+	img, err := s.RenderImage(result, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// parseColor converts a colour already checked to be "#rrggbb".
+func parseColor(hex string) color.RGBA {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+// drawFrame draws the one pixel wide border just inside r.
+func drawFrame(img draw.Image, r image.Rectangle, c color.Color) {
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Max.X, r.Min.Y+1), c)
+	fillRect(img, image.Rect(r.Min.X, r.Max.Y-1, r.Max.X, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Min.X, r.Min.Y, r.Min.X+1, r.Max.Y), c)
+	fillRect(img, image.Rect(r.Max.X-1, r.Min.Y, r.Max.X, r.Max.Y), c)
+}
+
+// drawLoops strokes outline loops with lines of the given width, centred on
+// the grid lines. The outlines only have horizontal and vertical sides.
+func drawLoops(img draw.Image, loops [][]point, l layout, width int, c color.Color) {
+	for _, loop := range loops {
+		for i, p := range loop {
+			x0, y0 := l.corner(p)
+			x1, y1 := l.corner(loop[(i+1)%len(loop)])
+			fillRect(img, image.Rect(
+				min(x0, x1)-width/2, min(y0, y1)-width/2,
+				max(x0, x1)+(width+1)/2, max(y0, y1)+(width+1)/2,
+			), c)
+		}
+	}
+}
+
+// fitText returns the largest font scale up to scale at which text fits in
+// width pixels, but at least 1.
+func fitText(text string, scale, width int) int {
+	for scale > 1 && textWidth(text)*scale > width {
+		scale--
+	}
+	return scale
+}
+
+// drawText writes text with its top left corner at x, y, every font pixel
+// drawn as a scale by scale square. Bold text is drawn twice, one pixel apart.
+func drawText(img draw.Image, text string, x, y, scale int, c color.Color, bold bool) {
+	weight := 0
+	if bold && scale > 1 {
+		weight = 1
+	}
+	for i, r := range []rune(text) {
+		g := glyph(r)
+		for row := range glyphHeight {
+			for col := range glyphWidth {
+				if g[row][col] != '#' {
+					continue
+				}
+				px, py := x+(i*glyphAdvance+col)*scale, y+row*scale
+				fillRect(img, image.Rect(px, py, px+scale+weight, py+scale), c)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProgressInterval is used by WithProgress when no interval is given.
+const DefaultProgressInterval = 250 * time.Millisecond
+
+// Progress is a snapshot of a running solve, delivered to the callback set
+// with WithProgress.
+type Progress struct {
+	Elapsed        time.Duration
+	Attempts       int64   // Search nodes visited so far by all workers
+	Pruned         int64   // Placements rejected by dead-region pruning so far
+	NodesPerSecond float64 // Rate since the previous event
+	Depth          int     // Deepest position currently searched by any worker
+	Workers        []WorkerProgress
+	Done           bool // Set on the final event once every worker has stopped
+}
+
+// WorkerProgress is the status of a single search worker.
+type WorkerProgress struct {
+	ID    int
+	Nodes int64 // Search nodes visited by this worker
+	Depth int   // Pieces placed on the board the worker is searching
+	Idle  bool  // The worker has run out of work or has stopped
+}
+
+// WithProgress calls fn every interval while a solve is running and once
+// more when it has finished. fn runs on its own goroutine and should return
+// quickly.
+func WithProgress(interval time.Duration, fn func(Progress)) Option {
+	return func(o *SolverOptions) {
+		o.ProgressInterval = interval
+		o.Progress = fn
+	}
+}
+
+// watchProgress starts reporting progress for the solve and returns a function
+// that stops reporting and sends the final event. It must be called after
+// startSearch and the returned function after every worker has finished.
+func (s *CalendarBoardSolver) watchProgress(state *searchState, startTime time.Time) func() {
+	if s.Progress == nil {
+		return func() {}
+	}
+
+	interval := s.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	lastAttempts, lastTime := int64(0), startTime
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				progress := state.progress(startTime, now)
+				if seconds := now.Sub(lastTime).Seconds(); seconds > 0 {
+					progress.NodesPerSecond = float64(progress.Attempts-lastAttempts) / seconds
+				}
+				lastAttempts, lastTime = progress.Attempts, now
+				s.Progress(progress)
+			}
+		}
+	}()
+
+	return func() {
+		close(stop)
+		<-stopped
+
+		progress := state.progress(startTime, time.Now())
+		if seconds := progress.Elapsed.Seconds(); seconds > 0 {
+			progress.NodesPerSecond = float64(progress.Attempts) / seconds
+		}
+		progress.Done = true
+		s.Progress(progress)
+	}
+}
+
+func (state *searchState) progress(startTime, now time.Time) Progress {
+	progress := Progress{
+		Elapsed:  now.Sub(startTime),
+		Attempts: atomic.LoadInt64(state.globalAttempts),
+		Pruned:   state.pruned.Load(),
+		Workers:  make([]WorkerProgress, len(state.workerNodes)),
+	}
+
+	for i := range state.workerNodes {
+		worker := WorkerProgress{
+			ID:    i,
+			Nodes: atomic.LoadInt64(&state.workerNodes[i]),
+			Depth: int(atomic.LoadInt32(&state.workerDepth[i])),
+			Idle:  atomic.LoadInt32(&state.workerIdle[i]) != 0,
+		}
+		if !worker.Idle && worker.Depth > progress.Depth {
+			progress.Depth = worker.Depth
+		}
+		progress.Workers[i] = worker
+	}
+
+	return progress
+}
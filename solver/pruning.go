@@ -0,0 +1,65 @@
+package solver
+
+import "math/bits"
+
+// floodRegion returns the cells of within that are orthogonally connected to
+// seed.
+func (state *searchState) floodRegion(seed, within uint64) uint64 {
+	region := seed
+	for {
+		grown := region |
+			(region<<1)&^state.firstColumn |
+			(region>>1)&^state.lastColumn |
+			region<<state.cols |
+			region>>state.cols
+		grown &= within
+		if grown == region {
+			return region
+		}
+		region = grown
+	}
+}
+
+// hasDeadRegion reports whether the empty cells contain a connected region
+// that the unused pieces cannot fill: one smaller than the smallest unused
+// piece, or one whose size is not a sum of unused piece sizes.
+func (state *searchState) hasDeadRegion(board uint64, pieceMasks []uint64) bool {
+	// Bit n of sums is set when some subset of the unused pieces covers n cells
+	sums := uint64(1)
+	smallest := maxBoardCells
+	for i, mask := range pieceMasks {
+		if mask != 0 {
+			continue
+		}
+		size := state.pieceSizes[i]
+		sums |= sums << size
+		if size < smallest {
+			smallest = size
+		}
+	}
+
+	empty := state.target &^ board
+	for empty != 0 {
+		region := state.floodRegion(empty&-empty, empty)
+		empty &^= region
+
+		size := bits.OnesCount64(region)
+		if size < smallest || sums&(1<<size) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneAfterPlacement checks the board right after a placement and counts the
+// node as pruned if it left a region that cannot be filled.
+func (s *CalendarBoardSolver) pruneAfterPlacement(state *searchState, work *WorkItem) bool {
+	if s.DisablePruning || work.Board == state.target {
+		return false
+	}
+	if state.hasDeadRegion(work.Board, work.PieceMasks) {
+		state.pruned.Add(1)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,224 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RenderOptions controls the image renderers. The zero value gives the
+// defaults.
+type RenderOptions struct {
+	CellSize int           // Pixels per board cell, 0 means 60
+	Title    string        // Caption above the board, optional
+	Palette  []string      // Piece colours as "#rrggbb", repeated as needed; empty means the pieces' own colours
+	Delay    time.Duration // Time between the frames of an animation, 0 means 700ms
+}
+
+const (
+	defaultCellSize = 60
+	defaultDelay    = 700 * time.Millisecond
+)
+
+func (o RenderOptions) cellSize() int {
+	if o.CellSize > 0 {
+		return o.CellSize
+	}
+	return defaultCellSize
+}
+
+func (o RenderOptions) delay() time.Duration {
+	if o.Delay > 0 {
+		return o.Delay
+	}
+	return defaultDelay
+}
+
+// defaultPieceColors is used for pieces without a colour of their own.
+var defaultPieceColors = []string{
+	"#ef4444", "#22c55e", "#3b82f6", "#eab308", "#a855f7",
+	"#ec4899", "#6366f1", "#14b8a6", "#f97316", "#65a30d",
+}
+
+// pieceColor returns the display colour of a piece as "#rrggbb".
+func (s *CalendarBoardSolver) pieceColor(index int) string {
+	if index < len(s.PieceColors) && s.PieceColors[index] != "" {
+		return s.PieceColors[index]
+	}
+	return defaultPieceColors[index%len(defaultPieceColors)]
+}
+
+// pieceColors returns the colour every piece is drawn in as "#rrggbb", taken
+// from the palette if one is given.
+func (s *CalendarBoardSolver) pieceColors(opts RenderOptions) ([]string, error) {
+	for _, color := range opts.Palette {
+		if !colorPattern.MatchString(color) {
+			return nil, fmt.Errorf("invalid palette: color %q is not #rrggbb", color)
+		}
+	}
+	colors := make([]string, len(s.Pieces))
+	for i := range colors {
+		if len(opts.Palette) > 0 {
+			colors[i] = strings.ToLower(opts.Palette[i%len(opts.Palette)])
+		} else {
+			colors[i] = s.pieceColor(i)
+		}
+	}
+	return colors, nil
+}
+
+// layout is where the renderers place the board in an image, in pixels.
+type layout struct {
+	cell, margin, top int // Cell size and the distance from the image edges to the board
+	width, height     int
+}
+
+func (s *CalendarBoardSolver) layout(opts RenderOptions) layout {
+	l := layout{cell: opts.cellSize()}
+	l.margin = l.cell / 4
+	l.top = l.margin
+	if opts.Title != "" {
+		l.top += l.cell / 2
+	}
+	l.width = s.Cols*l.cell + 2*l.margin
+	l.height = s.Rows*l.cell + l.top + l.margin
+	return l
+}
+
+// corner returns the pixel position of a corner of the cell grid.
+func (l layout) corner(p point) (int, int) {
+	return l.margin + p.x*l.cell, l.top + p.y*l.cell
+}
+
+// cellLabels returns the text printed on every labelled cell of the board.
+func (s *CalendarBoardSolver) cellLabels() map[Position]string {
+	labels := make(map[Position]string)
+	for month, pos := range s.MonthPositions {
+		labels[pos] = month
+	}
+	for day, pos := range s.DayPositions {
+		labels[pos] = fmt.Sprint(day)
+	}
+	for weekday, pos := range s.WeekdayPositions {
+		labels[pos] = s.Weekdays[weekday]
+	}
+	return labels
+}
+
+// pieceCells groups the cells of a solution by piece, indexed from 0.
+func (s *CalendarBoardSolver) pieceCells(pieceMap map[Position]int) [][]Position {
+	cells := make([][]Position, len(s.Pieces))
+	for pos, pieceNum := range pieceMap {
+		if pieceNum >= 1 && pieceNum <= len(cells) {
+			cells[pieceNum-1] = append(cells[pieceNum-1], pos)
+		}
+	}
+	return cells
+}
+
+// point is a corner of the cell grid, x to the right and y down.
+type point struct {
+	x, y int
+}
+
+// outline traces the boundary of a set of cells as closed loops of grid
+// corners, clockwise on screen, without points in the middle of straight
+// sides. Cells that only touch at a corner get separate loops.
+func outline(cells []Position) [][]point {
+	inside := make(map[Position]bool, len(cells))
+	for _, cell := range cells {
+		inside[cell] = true
+	}
+
+	// Every side facing a cell outside the set is an edge, directed so that
+	// the set lies on its right
+	edges := make(map[point][]point)
+	addEdge := func(from, to point) {
+		edges[from] = append(edges[from], to)
+	}
+	for _, cell := range cells {
+		x, y := cell.Col, cell.Row
+		if !inside[Position{cell.Row - 1, cell.Col}] {
+			addEdge(point{x, y}, point{x + 1, y})
+		}
+		if !inside[Position{cell.Row, cell.Col + 1}] {
+			addEdge(point{x + 1, y}, point{x + 1, y + 1})
+		}
+		if !inside[Position{cell.Row + 1, cell.Col}] {
+			addEdge(point{x + 1, y + 1}, point{x, y + 1})
+		}
+		if !inside[Position{cell.Row, cell.Col - 1}] {
+			addEdge(point{x, y + 1}, point{x, y})
+		}
+	}
+
+	starts := make([]point, 0, len(edges))
+	for start := range edges {
+		starts = append(starts, start)
+	}
+	sort.Slice(starts, func(i, j int) bool {
+		if starts[i].y == starts[j].y {
+			return starts[i].x < starts[j].x
+		}
+		return starts[i].y < starts[j].y
+	})
+
+	var loops [][]point
+	for _, start := range starts {
+		for len(edges[start]) > 0 {
+			loop := []point{start}
+			current, dx, dy := start, 0, 0
+			for {
+				next := takeEdge(edges, current, dx, dy)
+				dx, dy = next.x-current.x, next.y-current.y
+				current = next
+				if current == start {
+					break
+				}
+				loop = append(loop, current)
+			}
+			loops = append(loops, simplifyLoop(loop))
+		}
+	}
+	return loops
+}
+
+// takeEdge removes and returns the end of an edge leaving from. Where two
+// loops touch at a corner it turns right first, so each loop stays separate.
+func takeEdge(edges map[point][]point, from point, dx, dy int) point {
+	candidates := edges[from]
+	best := 0
+	for _, dir := range [][2]int{{-dy, dx}, {dx, dy}, {dy, -dx}} {
+		found := false
+		for i, to := range candidates {
+			if to.x-from.x == dir[0] && to.y-from.y == dir[1] {
+				best, found = i, true
+				break
+			}
+		}
+		if found {
+			break
+		}
+	}
+
+	to := candidates[best]
+	edges[from] = append(candidates[:best:best], candidates[best+1:]...)
+	if len(edges[from]) == 0 {
+		delete(edges, from)
+	}
+	return to
+}
+
+// simplifyLoop drops the corners where a loop goes straight on.
+func simplifyLoop(loop []point) []point {
+	simplified := make([]point, 0, len(loop))
+	for i, p := range loop {
+		prev := loop[(i+len(loop)-1)%len(loop)]
+		next := loop[(i+1)%len(loop)]
+		if (p.x-prev.x)*(next.y-p.y) != (p.y-prev.y)*(next.x-p.x) {
+			simplified = append(simplified, p)
+		}
+	}
+	return simplified
+}
@@ -0,0 +1,340 @@
+package solver
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"context"
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"time"
+)
+
+// The solution database file starts with solutionDBMagic followed by the
+// format version as a uvarint. The rest of the file is DEFLATE compressed and
+// holds little endian or uvarint encoded fields:
+//
+//	fingerprint  uint64   Solver.Fingerprint of the board and pieces
+//	pieces       uvarint  Number of pieces in every stored solution
+//	entries      uvarint  Number of dates
+//	per date:    month (1-12), day, count, stored solutions, then for every
+//	             stored solution one cell mask per piece, all uvarints
+const (
+	solutionDBMagic   = "CSDB"
+	solutionDBVersion = 1
+)
+
+//go:embed solutions.db
+var embeddedSolutionDB []byte
+
+// SolutionDB holds precomputed solutions and solution counts for calendar
+// dates. It only answers for solvers whose Fingerprint matches.
+type SolutionDB struct {
+	Fingerprint uint64
+	NumPieces   int
+	entries     map[dbDate]dbEntry
+}
+
+type dbDate struct {
+	month, day int // month is 1-based
+}
+
+type dbEntry struct {
+	count     int64
+	solutions [][]uint64 // Cell mask of each piece, in piece order
+}
+
+// Len returns the number of dates in the database.
+func (db *SolutionDB) Len() int {
+	return len(db.entries)
+}
+
+// Count returns the number of solutions stored for a 1-based month and day.
+func (db *SolutionDB) Count(month, day int) (int64, bool) {
+	entry, ok := db.entries[dbDate{month, day}]
+	return entry.count, ok
+}
+
+// EmbeddedSolutionDB returns the database built into the package for the
+// default board and pieces.
+func EmbeddedSolutionDB() (*SolutionDB, error) {
+	return ReadSolutionDB(bytes.NewReader(embeddedSolutionDB))
+}
+
+// ReadSolutionDB decodes a database written by WriteTo.
+func ReadSolutionDB(r io.Reader) (*SolutionDB, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(solutionDBMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != solutionDBMagic {
+		return nil, fmt.Errorf("invalid solution database: missing %s header", solutionDBMagic)
+	}
+	version, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("invalid solution database: %w", err)
+	}
+	if version != solutionDBVersion {
+		return nil, fmt.Errorf("invalid solution database: unsupported version %d", version)
+	}
+
+	body := flate.NewReader(br)
+	defer body.Close()
+	br = bufio.NewReader(body)
+
+	db := &SolutionDB{entries: make(map[dbDate]dbEntry)}
+	if err := binary.Read(br, binary.LittleEndian, &db.Fingerprint); err != nil {
+		return nil, fmt.Errorf("invalid solution database: %w", err)
+	}
+
+	// Every remaining field is a uvarint
+	var readErr error
+	next := func() uint64 {
+		if readErr != nil {
+			return 0
+		}
+		var v uint64
+		v, readErr = binary.ReadUvarint(br)
+		return v
+	}
+
+	// Sizes are checked before anything is allocated from them, so a corrupt
+	// file cannot ask for huge slices
+	numPieces := next()
+	if readErr == nil && (numPieces == 0 || numPieces > maxBoardCells) {
+		return nil, fmt.Errorf("invalid solution database: %d pieces", numPieces)
+	}
+	db.NumPieces = int(numPieces)
+	numEntries := next()
+	for i := uint64(0); i < numEntries && readErr == nil; i++ {
+		month, day := next(), next()
+		if readErr == nil && (month < 1 || month > 12 || day < 1 || day > 31) {
+			return nil, fmt.Errorf("invalid solution database: date %d/%d", day, month)
+		}
+		date := dbDate{month: int(month), day: int(day)}
+		entry := dbEntry{count: int64(next())}
+		stored := next()
+		for j := uint64(0); j < stored && readErr == nil; j++ {
+			masks := make([]uint64, db.NumPieces)
+			var board uint64
+			for k := range masks {
+				masks[k] = next()
+				if readErr == nil && (masks[k] == 0 || masks[k]&board != 0) {
+					return nil, fmt.Errorf("invalid solution database: bad cell mask %#x for %d/%d", masks[k], day, month)
+				}
+				board |= masks[k]
+			}
+			entry.solutions = append(entry.solutions, masks)
+		}
+		db.entries[date] = entry
+	}
+	if readErr != nil {
+		if errors.Is(readErr, io.EOF) {
+			readErr = io.ErrUnexpectedEOF
+		}
+		return nil, fmt.Errorf("invalid solution database: %w", readErr)
+	}
+	return db, nil
+}
+
+// WriteTo encodes the database in the versioned binary format, with dates in
+// calendar order.
+func (db *SolutionDB) WriteTo(w io.Writer) (int64, error) {
+	dates := make([]dbDate, 0, len(db.entries))
+	for date := range db.entries {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		if dates[i].month == dates[j].month {
+			return dates[i].day < dates[j].day
+		}
+		return dates[i].month < dates[j].month
+	})
+
+	buf := binary.LittleEndian.AppendUint64(nil, db.Fingerprint)
+	buf = binary.AppendUvarint(buf, uint64(db.NumPieces))
+	buf = binary.AppendUvarint(buf, uint64(len(dates)))
+	for _, date := range dates {
+		entry := db.entries[date]
+		buf = binary.AppendUvarint(buf, uint64(date.month))
+		buf = binary.AppendUvarint(buf, uint64(date.day))
+		buf = binary.AppendUvarint(buf, uint64(entry.count))
+		buf = binary.AppendUvarint(buf, uint64(len(entry.solutions)))
+		for _, masks := range entry.solutions {
+			for _, mask := range masks {
+				buf = binary.AppendUvarint(buf, mask)
+			}
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString(solutionDBMagic)
+	file.Write(binary.AppendUvarint(nil, solutionDBVersion))
+	body, err := flate.NewWriter(&file, flate.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := body.Write(buf); err != nil {
+		return 0, err
+	}
+	if err := body.Close(); err != nil {
+		return 0, err
+	}
+	return file.WriteTo(w)
+}
+
+// BuildSolutionDB solves every day of every month, including 29 February, and
+// keeps the solution count and up to perDate solutions for each date. onDate,
+// if not nil, is called after each date.
+func (s *CalendarBoardSolver) BuildSolutionDB(ctx context.Context, perDate int, onDate func(month, day int, count int64)) (*SolutionDB, error) {
+	if len(s.WeekdayPositions) > 0 {
+		return nil, fmt.Errorf("invalid board: solution databases do not support weekday boards")
+	}
+
+	db := &SolutionDB{
+		Fingerprint: s.Fingerprint(),
+		NumPieces:   len(s.Pieces),
+		entries:     make(map[dbDate]dbEntry),
+	}
+
+	for monthIndex, month := range s.Months {
+		for day := 1; day <= daysIn(time.Month(monthIndex+1), 0); day++ {
+			result, err := s.SolveAllContext(ctx, day, month)
+			if err != nil {
+				return nil, fmt.Errorf("solving %d %s: %w", day, month, err)
+			}
+
+			entry := dbEntry{count: int64(result.Count)}
+			for _, solution := range result.Solutions {
+				if len(entry.solutions) == perDate {
+					break
+				}
+				entry.solutions = append(entry.solutions, s.pieceMasksFromMap(solution.PieceMap))
+			}
+			db.entries[dbDate{monthIndex + 1, day}] = entry
+
+			if onDate != nil {
+				onDate(monthIndex+1, day, entry.count)
+			}
+		}
+	}
+	return db, nil
+}
+
+func (s *CalendarBoardSolver) pieceMasksFromMap(pieceMap map[Position]int) []uint64 {
+	masks := make([]uint64, len(s.Pieces))
+	for pos, pieceNum := range pieceMap {
+		masks[pieceNum-1] |= s.cellBit(pos)
+	}
+	return masks
+}
+
+// Fingerprint identifies the board layout, the pieces and the orientation
+// rule. A solution database only applies to solvers with the same fingerprint.
+func (s *CalendarBoardSolver) Fingerprint() uint64 {
+	h := fnv.New64a()
+	write := func(values ...int) {
+		for _, v := range values {
+			binary.Write(h, binary.LittleEndian, int64(v))
+		}
+	}
+
+	write(s.Rows, s.Cols)
+	write(len(s.Months))
+	for _, month := range s.Months {
+		pos := s.MonthPositions[month]
+		write(pos.Row, pos.Col)
+	}
+	write(len(s.DayPositions))
+	for day := 1; day <= len(s.DayPositions); day++ {
+		pos := s.DayPositions[day]
+		write(pos.Row, pos.Col)
+	}
+	write(len(s.WeekdayPositions))
+	for weekday := time.Sunday; int(weekday) < len(s.WeekdayPositions); weekday++ {
+		pos := s.WeekdayPositions[weekday]
+		write(pos.Row, pos.Col)
+	}
+	write(len(s.FixedCells))
+	for _, pos := range s.FixedCells {
+		write(pos.Row, pos.Col)
+	}
+	write(len(s.Pieces))
+	for _, piece := range s.Pieces {
+		write(len(piece))
+		for _, pos := range piece {
+			write(pos.Row, pos.Col)
+		}
+	}
+	write(int(s.Orientations))
+	return h.Sum64()
+}
+
+// lookupSolutionDB returns the database entry for a date, if a database is
+// configured and was built for this solver.
+func (s *CalendarBoardSolver) lookupSolutionDB(date calendarDate) (dbEntry, bool) {
+	if s.SolutionDB == nil || date.blocked != nil {
+		return dbEntry{}, false
+	}
+	if s.SolutionDB.Fingerprint != s.Fingerprint() || s.SolutionDB.NumPieces != len(s.Pieces) {
+		s.logger().Debug("solution database does not match the board", "day", date.day, "month", date.month)
+		return dbEntry{}, false
+	}
+
+	for i, month := range s.Months {
+		if month == date.month {
+			entry, ok := s.SolutionDB.entries[dbDate{i + 1, date.day}]
+			if ok {
+				s.logger().Debug("using solution database", "day", date.day, "month", date.month, "count", entry.count)
+			}
+			return entry, ok
+		}
+	}
+	return dbEntry{}, false
+}
+
+// solveFromDB answers a first-solution solve from the solution database.
+// With a random seed the stored solution is picked by the seed.
+func (s *CalendarBoardSolver) solveFromDB(date calendarDate) (SolveResult, bool) {
+	entry, ok := s.lookupSolutionDB(date)
+	if !ok || (entry.count > 0 && len(entry.solutions) == 0) {
+		return SolveResult{}, false
+	}
+	if entry.count == 0 {
+		return SolveResult{Precomputed: true}, true
+	}
+
+	pick := 0
+	if s.Shuffle {
+		// A deterministic pick must come from every solution, see solveCanonical
+		if s.Deterministic && int64(len(entry.solutions)) != entry.count {
+			return SolveResult{}, false
+		}
+		pick = int(s.Seed % uint64(len(entry.solutions)))
+	}
+	masks := entry.solutions[pick]
+
+	// A database with a matching fingerprint can still hold masks that are
+	// not legal placements; search instead of returning a broken solution
+	var board uint64
+	for _, mask := range masks {
+		board |= mask
+	}
+	placements := s.placementsFromMasks(masks)
+	blocked, err := s.blockedCells(date)
+	if err != nil || len(placements) != len(s.Pieces) || board|blocked != s.calendarMask() || board&blocked != 0 {
+		s.logger().Debug("solution database entry does not fit the board", "day", date.day, "month", date.month)
+		return SolveResult{}, false
+	}
+	return SolveResult{
+		Solution:    s.positionsFromMask(board),
+		PieceMap:    s.pieceMapFromMasks(masks),
+		Placements:  placements,
+		Found:       true,
+		Precomputed: true,
+	}, true
+}
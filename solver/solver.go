@@ -1,8 +1,10 @@
 package solver
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"runtime"
+	"math/bits"
 	"sort"
 	"strings"
 	"sync"
@@ -17,116 +19,148 @@ type Position struct {
 type Piece []Position
 
 type CalendarBoardSolver struct {
+	Rows, Cols     int // Board size, at most 64 cells in total
 	Months         []string
 	MonthPositions map[string]Position
 	DayPositions   map[int]Position
 	Pieces         []Piece
+	PieceNames     []string   // Shape name of each piece, optional
+	PieceColors    []string   // Display colour of each piece as "#rrggbb", optional
+	FixedCells     []Position // Playable cells without a label, always covered
+
+	// Weekday cells, only present on weekday boards. Weekdays holds the
+	// labels indexed by time.Weekday.
+	Weekdays         []string
+	WeekdayPositions map[time.Weekday]Position
+
+	SolverOptions
+
+	placementsOnce sync.Once
+	placements     [][]placement // Legal placements of each piece, see placementTable
 }
 
 type SolveResult struct {
 	Solution    []Position
-	PieceMap    map[Position]int // Maps position to piece number (1-8)
+	PieceMap    map[Position]int // Maps position to piece number, starting at 1
+	Placements  []Placement      // Where each piece lies, in piece order
+	Order       []int            // Piece indexes in the order the search placed them, nil from the database
 	Found       bool
 	SolveTime   time.Duration
 	Attempts    int64
+	Pruned      int64 // Placements rejected because they left an unfillable region
 	WorkerID    int
+	WorkerNodes []int64 // Search nodes visited by each worker
+	Precomputed bool    // Taken from the solution database without searching
 }
 
-type WorkItem struct {
-	Board      map[Position]bool
-	PieceMap   map[Position]int // Maps position to piece number (1-8)
-	UsedPieces []bool
-	Depth      int
+type SolveAllResult struct {
+	Solutions   []SolveResult // Only filled by SolveAll
+	Count       int
+	SolveTime   time.Duration
+	Attempts    int64
+	Pruned      int64
+	WorkerNodes []int64 // Search nodes visited by each worker
 }
 
-func NewCalendarBoardSolver() *CalendarBoardSolver {
-	solver := &CalendarBoardSolver{
-		Months: []string{
-			"Янв", "Фев", "Март", "Апр", "Май", "Июнь",
-			"Июль", "Авг", "Сент", "Окт", "Нояб", "Дек",
-		},
-		MonthPositions: make(map[string]Position),
-		DayPositions:   make(map[int]Position),
-	}
+type CountResult struct {
+	Count       int64
+	SolveTime   time.Duration
+	Attempts    int64
+	Pruned      int64
+	WorkerNodes []int64 // Search nodes visited by each worker
+	Precomputed bool    // Taken from the solution database without searching
+}
 
-	// Initialize month positions
-	months := []string{"Янв", "Фев", "Март", "Апр", "Май", "Июнь", "Июль", "Авг", "Сент", "Окт", "Нояб", "Дек"}
-	for i, month := range months {
-		if i < 6 {
-			solver.MonthPositions[month] = Position{0, i}
-		} else {
-			solver.MonthPositions[month] = Position{1, i - 6}
-		}
-	}
+type searchMode int
 
-	// Initialize day positions
-	day := 1
-	for row := 2; row < 7; row++ {
-		for col := 0; col < 7 && day <= 31; col++ {
-			solver.DayPositions[day] = Position{row, col}
-			day++
-		}
-	}
+const (
+	searchFirst searchMode = iota // Stop at the first solution
+	searchAll                     // Report every solution
+	searchCount                   // Only count solutions
+)
 
-	// Initialize pieces - these are the 8 unique pieces that total 41 cells
-	solver.Pieces = []Piece{
-		// Piece 1: L-shape (5 cells)
-		{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {2, 2}},
-		// Piece 2: Long L (5 cells)
-		{{0, 0}, {1, 0}, {2, 0}, {3, 0}, {3, 1}},
-		// Piece 3: Cut Rectangle (5 cells)
-		{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {1, 2}},
-		// Piece 4: Rectangle (6 cells)
-		{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}},
-		// Piece 5: T-shape (5 cells)
-		{{0, 0}, {0, 2}, {1, 0}, {1, 1}, {1, 2}},
-		// Piece 6: Z-shape (5 cells)
-		{{0, 0}, {0, 1}, {1, 1}, {2, 1}, {2, 2}},
-		// Piece 7: P-shape
-		{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {3, 0}},
-		// Piece 8: Stair Shape (5 cells)
-		{{0, 0}, {1, 0}, {2, 0}, {2, 1}, {3, 1}},
-	}
+// searchState is shared by all workers of a single solve.
+type searchState struct {
+	mode           searchMode
+	target         uint64        // Cells that must be covered
+	placements     [][]placement // Placements of each piece that avoid the blocked cells
+	cellOptions    [][]move      // Placements covering each cell, for StrategyMostConstrained
+	pieceSizes     []int         // Cells of each piece, for dead-region pruning
+	identical      []int         // Earlier piece with the same shape, see identicalPieces
+	cols           int           // Board width, the bit distance between rows
+	firstColumn    uint64        // Cells in the first column
+	lastColumn     uint64        // Cells in the last column
+	pruned         atomic.Int64  // Placements rejected by dead-region pruning
+	globalAttempts *int64
+	workerNodes    []int64 // Nodes visited by each worker, set by startSearch
+	workerDepth    []int32 // Depth each worker is currently searching
+	workerIdle     []int32 // Non-zero once a worker has stopped
+	solutionCount  *int64
+	resultChan     chan<- SolveResult
+	done           <-chan struct{} // Closed when the search must stop
+}
+
+type WorkItem struct {
+	Board      uint64   // Bitmask of occupied cells
+	PieceMasks []uint64 // Cells covered by each piece, zero while unused
+	Depth      int
+
+	placed []int // Pieces in the order they were placed
+}
+
+// NewCalendarBoardSolver returns a solver for the classic 7x7 board with its
+// eight pieces.
+func NewCalendarBoardSolver(opts ...Option) *CalendarBoardSolver {
+	return NewBoardSolver(builtinBoard("classic"), opts...)
+}
 
-	return solver
+// pieceName returns a display name such as "Piece 3: Cut Rectangle".
+func (s *CalendarBoardSolver) pieceName(index int) string {
+	if index < len(s.PieceNames) && s.PieceNames[index] != "" {
+		return fmt.Sprintf("Piece %d: %s", index+1, s.PieceNames[index])
+	}
+	return fmt.Sprintf("Piece %d", index+1)
 }
 
 func (s *CalendarBoardSolver) getAllOrientations(piece Piece) []Piece {
+	orientations, _ := s.orientationsWithTransforms(piece)
+	return orientations
+}
+
+// orientationsWithTransforms returns the distinct orientations of a piece
+// together with the transform that produces each of them from the piece as
+// defined.
+func (s *CalendarBoardSolver) orientationsWithTransforms(piece Piece) ([]Piece, []Transform) {
 	orientations := make([]Piece, 0, 8)
+	transforms := make([]Transform, 0, 8)
 	seen := make(map[string]bool)
 
-	// Generate all 4 rotations
-	current := piece
-	for i := 0; i < 4; i++ {
-		// Add current rotation
-		normalized := s.normalizePiece(current)
-		key := s.pieceToString(normalized)
-		if !seen[key] {
-			orientations = append(orientations, normalized)
-			seen[key] = true
+	for _, flipped := range []bool{false, true} {
+		if flipped && s.Orientations == OrientationsRotateOnly {
+			break
 		}
 
-		// Rotate 90 degrees clockwise for next iteration
-		current = s.rotatePiece90(current)
-	}
-
-	// Generate all 4 rotations of the flipped piece
-	flipped := s.flipHorizontal(piece)
-	current = flipped
-	for i := 0; i < 4; i++ {
-		// Add current rotation of flipped piece
-		normalized := s.normalizePiece(current)
-		key := s.pieceToString(normalized)
-		if !seen[key] {
-			orientations = append(orientations, normalized)
-			seen[key] = true
+		// Generate all 4 rotations of the piece or of its mirror image
+		current := piece
+		if flipped {
+			current = s.flipHorizontal(piece)
 		}
+		for i := 0; i < 4; i++ {
+			// Add current rotation
+			normalized := s.normalizePiece(current)
+			key := s.pieceToString(normalized)
+			if !seen[key] {
+				orientations = append(orientations, normalized)
+				transforms = append(transforms, Transform{Rotation: 90 * i, Flipped: flipped})
+				seen[key] = true
+			}
 
-		// Rotate 90 degrees clockwise for next iteration
-		current = s.rotatePiece90(current)
+			// Rotate 90 degrees clockwise for next iteration
+			current = s.rotatePiece90(current)
+		}
 	}
 
-	return orientations
+	return orientations, transforms
 }
 
 func (s *CalendarBoardSolver) rotatePiece90(piece Piece) Piece {
@@ -186,298 +220,694 @@ func (s *CalendarBoardSolver) pieceToString(piece Piece) string {
 	return strings.Join(positions, ";")
 }
 
-func (s *CalendarBoardSolver) isValidCalendarPosition(row, col int) bool {
-	// Month positions (rows 0-1, cols 0-5)
-	if row >= 0 && row <= 1 && col >= 0 && col <= 5 {
-		return true
-	}
-	// Day positions (rows 2-6, cols 0-6, but only first 31 positions)
-	if row >= 2 && row <= 6 && col >= 0 && col <= 6 {
-		dayNum := (row-2)*7 + col + 1
-		return dayNum <= 31
-	}
-	return false
+func (s *CalendarBoardSolver) canPlacePiece(board, placement uint64) bool {
+	// Placements are precomputed to stay on the calendar and off the blocked
+	// date, so only overlaps with other pieces are left to check
+	return placement&board == 0
 }
 
-func (s *CalendarBoardSolver) canPlacePiece(board map[Position]bool, piece Piece, startRow, startCol int, blockedCells map[Position]bool) bool {
-	for _, offset := range piece {
-		pos := Position{startRow + offset.Row, startCol + offset.Col}
+func (s *CalendarBoardSolver) placePiece(work *WorkItem, pieceIndex int, placement uint64) {
+	work.Board |= placement
+	work.PieceMasks[pieceIndex] = placement
+	work.placed = append(work.placed, pieceIndex)
+	work.Depth++
+}
 
-		// Check bounds
-		if pos.Row < 0 || pos.Row >= 7 || pos.Col < 0 || pos.Col >= 7 {
-			return false
-		}
+func (s *CalendarBoardSolver) removePiece(work *WorkItem, pieceIndex int) {
+	work.Board &^= work.PieceMasks[pieceIndex]
+	work.PieceMasks[pieceIndex] = 0
+	work.placed = work.placed[:len(work.placed)-1]
+	work.Depth--
+}
 
-		// Check if already occupied
-		if board[pos] {
-			return false
-		}
+func (s *CalendarBoardSolver) prepareSolve(date calendarDate) (*searchState, error) {
+	// Get blocked positions
+	blocked, err := s.blockedCells(date)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if blocked (current date)
-		if blockedCells[pos] {
-			return false
-		}
+	// Target positions (all valid positions except blocked)
+	target := s.calendarMask() &^ blocked
+	targetSize := bits.OnesCount64(target)
 
-		// Check if valid calendar position
-		if !s.isValidCalendarPosition(pos.Row, pos.Col) {
-			return false
-		}
+	// Calculate total cells in all pieces
+	totalPieceCells := 0
+	for _, piece := range s.Pieces {
+		totalPieceCells += len(piece)
 	}
-	return true
-}
 
-func (s *CalendarBoardSolver) placePiece(board map[Position]bool, pieceMap map[Position]int, piece Piece, startRow, startCol int, pieceNumber int) (map[Position]bool, map[Position]int) {
-	newBoard := make(map[Position]bool)
-	for pos := range board {
-		newBoard[pos] = true
-	}
+	s.logger().Debug("preparing solve",
+		"day", date.day,
+		"month", date.month,
+		"weekday", date.weekdayLabel(s),
+		"target_cells", targetSize,
+		"piece_cells", totalPieceCells,
+		"strategy", s.Strategy.String(),
+		"workers", s.WorkerCount())
 
-	newPieceMap := make(map[Position]int)
-	for pos, pieceNum := range pieceMap {
-		newPieceMap[pos] = pieceNum
+	if totalPieceCells != targetSize {
+		return nil, &PieceAreaError{PieceCells: totalPieceCells, TargetCells: targetSize}
 	}
 
-	for _, offset := range piece {
-		pos := Position{startRow + offset.Row, startCol + offset.Col}
-		newBoard[pos] = true
-		newPieceMap[pos] = pieceNumber
+	state := &searchState{
+		target:     target,
+		placements: s.placementsFor(target),
+		pieceSizes: make([]int, len(s.Pieces)),
+		identical:  s.identicalPieces(),
+		cols:       s.Cols,
+	}
+	state.firstColumn, state.lastColumn = s.columnMasks()
+	for i, piece := range s.Pieces {
+		state.pieceSizes[i] = len(piece)
 	}
+	if s.Shuffle {
+		shufflePlacements(state.placements, s.Seed)
+	}
+	if s.Strategy == StrategyMostConstrained {
+		state.cellOptions = cellOptionsFor(state.placements)
+		if s.Shuffle {
+			shuffleCellOptions(state.cellOptions, s.Seed)
+		}
+	}
+	return state, nil
+}
 
-	return newBoard, newPieceMap
+// logUnreturnedError logs errors from the variants that cannot return them.
+// Cancellation is expected there, so only other errors are reported.
+func (s *CalendarBoardSolver) logUnreturnedError(err error, currentDay int, currentMonth string) {
+	if err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		s.logger().Warn("solve failed", "day", currentDay, "month", currentMonth, "error", err)
+	}
 }
 
+// SolveParallel finds a single solution for the given date, giving up after
+// the configured Timeout. Use SolveContext to control cancellation and to tell
+// a timeout apart from an exhausted search.
 func (s *CalendarBoardSolver) SolveParallel(currentDay int, currentMonth string) SolveResult {
-	startTime := time.Now()
+	ctx, cancel := s.timeoutContext(context.Background())
+	defer cancel()
 
-	// Get blocked positions
-	blockedCells := make(map[Position]bool)
-	blockedCells[s.MonthPositions[currentMonth]] = true
-	blockedCells[s.DayPositions[currentDay]] = true
-
-	// Get all valid positions
-	allPositions := make(map[Position]bool)
-	for _, pos := range s.MonthPositions {
-		allPositions[pos] = true
-	}
-	for _, pos := range s.DayPositions {
-		allPositions[pos] = true
-	}
+	result, err := s.SolveContext(ctx, currentDay, currentMonth)
+	s.logUnreturnedError(err, currentDay, currentMonth)
+	return result
+}
 
-	// Target positions (all except blocked)
-	targetPositions := make([]Position, 0)
-	for pos := range allPositions {
-		if !blockedCells[pos] {
-			targetPositions = append(targetPositions, pos)
-		}
+// timeoutContext derives a context bounded by the configured Timeout.
+func (s *CalendarBoardSolver) timeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.Timeout > 0 {
+		return context.WithTimeout(ctx, s.Timeout)
 	}
+	return context.WithCancel(ctx)
+}
 
-	targetSize := len(targetPositions)
+// SolveContext finds a single solution for the given date. When ctx is
+// cancelled or its deadline expires the workers stop and the partial
+// statistics are returned together with ctx.Err(), so callers can check for
+// context.Canceled or context.DeadlineExceeded. An exhausted search returns a
+// result with Found set to false and a nil error. A *PieceAreaError is
+// returned without searching when the pieces cannot fill the board.
+func (s *CalendarBoardSolver) SolveContext(ctx context.Context, currentDay int, currentMonth string) (SolveResult, error) {
+	return s.solveFirst(ctx, calendarDate{day: currentDay, month: currentMonth, weekday: noWeekday})
+}
 
-	fmt.Printf("Target positions to fill: %d\n", targetSize)
+func (s *CalendarBoardSolver) solveFirst(ctx context.Context, date calendarDate) (SolveResult, error) {
+	startTime := time.Now()
 
-	// Calculate total cells in all pieces
-	totalPieceCells := 0
-	for _, piece := range s.Pieces {
-		totalPieceCells += len(piece)
+	if result, ok := s.solveFromDB(date); ok {
+		result.SolveTime = time.Since(startTime)
+		return result, nil
+	}
+	if s.Deterministic {
+		return s.solveCanonical(ctx, date)
 	}
-	fmt.Printf("Total piece cells: %d\n", totalPieceCells)
 
-	if totalPieceCells != targetSize {
-		fmt.Printf("WARNING: Piece cells (%d) != target positions (%d)\n", totalPieceCells, targetSize)
+	state, err := s.prepareSolve(date)
+	if err != nil {
+		return SolveResult{SolveTime: time.Since(startTime)}, err
 	}
 
-	// Parallel solving setup
-	numWorkers := runtime.NumCPU()
-	runtime.GOMAXPROCS(numWorkers)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
+	// Parallel solving setup
 	resultChan := make(chan SolveResult, 1)
-	workChan := make(chan WorkItem, 10000)
-	doneChan := make(chan bool)
 
 	var globalAttempts int64
 	var wg sync.WaitGroup
 
-	// Start workers
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go s.worker(i, workChan, resultChan, doneChan, &globalAttempts, targetSize, blockedCells, &wg)
-	}
+	state.mode = searchFirst
+	state.globalAttempts = &globalAttempts
+	state.resultChan = resultChan
+	state.done = ctx.Done()
+
+	s.startSearch(state, &wg)
+	stopProgress := s.watchProgress(state, startTime)
 
-	// Generate initial work items
 	go func() {
-		defer close(workChan)
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// Wait for a result, an exhausted search or cancellation
+	var result SolveResult
+	select {
+	case result = <-resultChan:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
 
-		initialBoard := make(map[Position]bool)
-		initialPieceMap := make(map[Position]int)
-		usedPieces := make([]bool, len(s.Pieces))
+	// Stop the remaining workers before reporting so nothing keeps running
+	cancel()
+	wg.Wait()
+	stopProgress()
 
-		select {
-		case workChan <- WorkItem{
-			Board:      initialBoard,
-			PieceMap:   initialPieceMap,
-			UsedPieces: usedPieces,
-			Depth:      0,
-		}:
-		case <-doneChan:
-			return
+	result.SolveTime = time.Since(startTime)
+	result.Attempts = atomic.LoadInt64(&globalAttempts)
+	result.Pruned = state.pruned.Load()
+	result.WorkerNodes = state.nodeCounts()
+	return result, err
+}
+
+// solveCanonical returns the solution with the smallest PieceMapKey, or with
+// a seed the one it picks from all solutions in that order, so the answer is
+// the same for every strategy, number of workers and solution database. It
+// has to enumerate every solution to know which one that is.
+func (s *CalendarBoardSolver) solveCanonical(ctx context.Context, date calendarDate) (SolveResult, error) {
+	all, err := s.solveAll(ctx, date)
+
+	var result SolveResult
+	if err == nil && len(all.Solutions) > 0 {
+		pick := 0
+		if s.Shuffle {
+			pick = int(s.Seed % uint64(len(all.Solutions)))
+		}
+		result = all.Solutions[pick]
+	}
+	result.SolveTime = all.SolveTime
+	result.Attempts = all.Attempts
+	result.Pruned = all.Pruned
+	result.WorkerNodes = all.WorkerNodes
+	return result, err
+}
+
+// SolveAll walks the whole search tree and returns every distinct solution for
+// the given date, ordered by their board layout.
+func (s *CalendarBoardSolver) SolveAll(currentDay int, currentMonth string) SolveAllResult {
+	result, err := s.SolveAllContext(context.Background(), currentDay, currentMonth)
+	s.logUnreturnedError(err, currentDay, currentMonth)
+	return result
+}
+
+// SolveAllContext is like SolveAll but stops when ctx is done, returning the
+// solutions found so far together with ctx.Err().
+func (s *CalendarBoardSolver) SolveAllContext(ctx context.Context, currentDay int, currentMonth string) (SolveAllResult, error) {
+	return s.solveAll(ctx, calendarDate{day: currentDay, month: currentMonth, weekday: noWeekday})
+}
+
+func (s *CalendarBoardSolver) solveAll(ctx context.Context, date calendarDate) (SolveAllResult, error) {
+	solutionChan := make(chan SolveResult)
+	collected := make(chan []SolveResult)
+
+	go func() {
+		solutions := make([]SolveResult, 0)
+		for solution := range solutionChan {
+			solutions = append(solutions, solution)
 		}
+		sort.Slice(solutions, func(i, j int) bool {
+			return PieceMapKey(solutions[i].PieceMap) < PieceMapKey(solutions[j].PieceMap)
+		})
+		collected <- solutions
 	}()
 
-	// Wait for result or timeout
+	result, err := s.solveAllStream(ctx, date, solutionChan)
+	result.Solutions = <-collected
+	return result, err
+}
+
+// SolveAllStream sends every distinct solution for the given date to out as
+// soon as a worker finds it and closes out once the search tree is exhausted.
+// The returned summary does not carry the solutions themselves.
+func (s *CalendarBoardSolver) SolveAllStream(currentDay int, currentMonth string, out chan<- SolveResult) SolveAllResult {
+	result, err := s.SolveAllStreamContext(context.Background(), currentDay, currentMonth, out)
+	s.logUnreturnedError(err, currentDay, currentMonth)
+	return result
+}
+
+// SolveAllStreamContext is like SolveAllStream but stops when ctx is done. out
+// is closed in either case and the error is ctx.Err() if the search was
+// interrupted.
+func (s *CalendarBoardSolver) SolveAllStreamContext(ctx context.Context, currentDay int, currentMonth string, out chan<- SolveResult) (SolveAllResult, error) {
+	return s.solveAllStream(ctx, calendarDate{day: currentDay, month: currentMonth, weekday: noWeekday}, out)
+}
+
+func (s *CalendarBoardSolver) solveAllStream(ctx context.Context, date calendarDate, out chan<- SolveResult) (SolveAllResult, error) {
+	defer close(out)
+	startTime := time.Now()
+
+	state, err := s.prepareSolve(date)
+	if err != nil {
+		return SolveAllResult{SolveTime: time.Since(startTime)}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultChan := make(chan SolveResult, s.WorkerCount())
+
+	var globalAttempts int64
+	var wg sync.WaitGroup
+
+	state.mode = searchAll
+	state.globalAttempts = &globalAttempts
+	state.resultChan = resultChan
+	state.done = ctx.Done()
+
+	s.startSearch(state, &wg)
+	stopProgress := s.watchProgress(state, startTime)
+
 	go func() {
 		wg.Wait()
 		close(resultChan)
 	}()
 
-	// Get result with longer timeout for complex problems
-	select {
-	case result := <-resultChan:
-		close(doneChan)
+	// The workers search disjoint subtrees and canonical keeps one assignment
+	// of identical pieces, so every result is a new tiling and nothing needs
+	// to be remembered
+	count := 0
+	for result := range resultChan {
+		count++
 		result.SolveTime = time.Since(startTime)
 		result.Attempts = atomic.LoadInt64(&globalAttempts)
-		return result
-	case <-time.After(60 * time.Second): // Increased timeout to 60 seconds
-		close(doneChan)
-		return SolveResult{
-			Found:     false,
-			SolveTime: time.Since(startTime),
-			Attempts:  atomic.LoadInt64(&globalAttempts),
+		select {
+		case out <- result:
+		case <-ctx.Done():
+		}
+	}
+	stopProgress()
+
+	return SolveAllResult{
+		Count:       count,
+		SolveTime:   time.Since(startTime),
+		Attempts:    atomic.LoadInt64(&globalAttempts),
+		Pruned:      state.pruned.Load(),
+		WorkerNodes: state.nodeCounts(),
+	}, ctx.Err()
+}
+
+// CountSolutions returns the exact number of solutions for the given date. It
+// runs the same search as SolveAll but only counts the leaves it reaches.
+func (s *CalendarBoardSolver) CountSolutions(currentDay int, currentMonth string) CountResult {
+	result, err := s.CountSolutionsContext(context.Background(), currentDay, currentMonth)
+	s.logUnreturnedError(err, currentDay, currentMonth)
+	return result
+}
+
+// CountSolutionsContext is like CountSolutions but stops when ctx is done. The
+// count is then only a lower bound and the error is ctx.Err().
+func (s *CalendarBoardSolver) CountSolutionsContext(ctx context.Context, currentDay int, currentMonth string) (CountResult, error) {
+	return s.countSolutions(ctx, calendarDate{day: currentDay, month: currentMonth, weekday: noWeekday})
+}
+
+func (s *CalendarBoardSolver) countSolutions(ctx context.Context, date calendarDate) (CountResult, error) {
+	startTime := time.Now()
+
+	if entry, ok := s.lookupSolutionDB(date); ok {
+		return CountResult{Count: entry.count, SolveTime: time.Since(startTime), Precomputed: true}, nil
+	}
+
+	state, err := s.prepareSolve(date)
+	if err != nil {
+		return CountResult{SolveTime: time.Since(startTime)}, err
+	}
+
+	var globalAttempts, solutionCount int64
+	var wg sync.WaitGroup
+
+	state.mode = searchCount
+	state.globalAttempts = &globalAttempts
+	state.solutionCount = &solutionCount
+	state.done = ctx.Done()
+
+	s.startSearch(state, &wg)
+	stopProgress := s.watchProgress(state, startTime)
+	wg.Wait()
+	stopProgress()
+
+	return CountResult{
+		Count:       atomic.LoadInt64(&solutionCount),
+		SolveTime:   time.Since(startTime),
+		Attempts:    atomic.LoadInt64(&globalAttempts),
+		Pruned:      state.pruned.Load(),
+		WorkerNodes: state.nodeCounts(),
+	}, ctx.Err()
+}
+
+// startSearch launches the workers for the configured strategy and hands them
+// the initial work. Workers call wg.Done when they finish.
+func (s *CalendarBoardSolver) startSearch(state *searchState, wg *sync.WaitGroup) {
+	if s.Strategy == StrategyDLX {
+		// Dancing Links picks the most constrained column itself and runs on a
+		// single worker
+		state.setWorkers(1)
+		wg.Add(1)
+		go s.dlxWorker(0, state, wg)
+		return
+	}
+
+	numWorkers := s.WorkerCount()
+	state.setWorkers(numWorkers)
+
+	// Split the tree into independent subtrees up front so that every worker
+	// has something to search
+	items := s.splitWork(state, numWorkers*s.workItemsPerWorker())
+
+	workChan := make(chan WorkItem, len(items))
+	for _, item := range items {
+		workChan <- item
+	}
+	close(workChan)
+
+	for i := 0; i < numWorkers; i++ {
+		wg.Add(1)
+		go s.worker(i, workChan, state, wg)
+	}
+}
+
+// Work splitting stops once the tree has been expanded this many levels deep,
+// even if there are fewer items than requested.
+const maxSplitDepth = 3
+
+// splitWork expands the search tree breadth-first from the empty board until
+// it has at least minItems subtrees. The expanded nodes are counted as
+// attempts and placements are pruned as in the workers; the returned items
+// are searched by the workers.
+func (s *CalendarBoardSolver) splitWork(state *searchState, minItems int) []WorkItem {
+	items := []WorkItem{s.initialWorkItem()}
+
+	for depth := 0; depth < maxSplitDepth && len(items) < minItems; depth++ {
+		next := make([]WorkItem, 0, len(items))
+		for _, work := range items {
+			if work.Board == state.target {
+				// Leaves are left for the workers to report
+				next = append(next, work)
+				continue
+			}
+
+			atomic.AddInt64(state.globalAttempts, 1)
+			for _, option := range s.branches(state, &work) {
+				child := WorkItem{
+					Board:      work.Board,
+					PieceMasks: append([]uint64(nil), work.PieceMasks...),
+					Depth:      work.Depth,
+					placed:     append(make([]int, 0, len(s.Pieces)), work.placed...),
+				}
+				s.placePiece(&child, option.piece, option.mask)
+				if !s.pruneAfterPlacement(state, &child) {
+					next = append(next, child)
+				}
+			}
 		}
+		items = next
 	}
+
+	return items
+}
+
+// branches returns the placements the configured strategy tries below work,
+// each paired with the piece it places.
+func (s *CalendarBoardSolver) branches(state *searchState, work *WorkItem) []move {
+	options := make([]move, 0)
+
+	if s.Strategy == StrategyMostConstrained {
+		cell := s.mostConstrainedCell(state, work)
+		if cell == -1 {
+			return options
+		}
+		for _, option := range state.cellOptions[cell] {
+			if work.PieceMasks[option.piece] == 0 && s.canPlacePiece(work.Board, option.mask) {
+				options = append(options, option)
+			}
+		}
+		return options
+	}
+
+	nextPieceIndex := nextUnusedPiece(work.PieceMasks)
+	if nextPieceIndex == -1 {
+		return options
+	}
+	for _, p := range state.placements[nextPieceIndex] {
+		if s.canPlacePiece(work.Board, p.mask) {
+			options = append(options, move{piece: nextPieceIndex, mask: p.mask})
+		}
+	}
+	return options
+}
+
+func (state *searchState) setWorkers(numWorkers int) {
+	state.workerNodes = make([]int64, numWorkers)
+	state.workerDepth = make([]int32, numWorkers)
+	state.workerIdle = make([]int32, numWorkers)
+}
+
+// countNode records a visited search node at the given depth for the worker
+// and the whole solve.
+func (state *searchState) countNode(workerID, depth int) {
+	atomic.AddInt64(state.globalAttempts, 1)
+	nodes := atomic.AddInt64(&state.workerNodes[workerID], 1)
+	atomic.StoreInt32(&state.workerDepth[workerID], int32(depth))
+	maybeYield(nodes)
 }
 
-func (s *CalendarBoardSolver) worker(workerID int, workChan <-chan WorkItem, resultChan chan<- SolveResult, doneChan <-chan bool, globalAttempts *int64, targetSize int, blockedCells map[Position]bool, wg *sync.WaitGroup) {
+func (state *searchState) markIdle(workerID int) {
+	atomic.StoreInt32(&state.workerIdle[workerID], 1)
+}
+
+func (state *searchState) nodeCounts() []int64 {
+	counts := make([]int64, len(state.workerNodes))
+	for i := range state.workerNodes {
+		counts[i] = atomic.LoadInt64(&state.workerNodes[i])
+	}
+	return counts
+}
+
+func nextUnusedPiece(pieceMasks []uint64) int {
+	for i, mask := range pieceMasks {
+		if mask == 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *CalendarBoardSolver) initialWorkItem() WorkItem {
+	return WorkItem{
+		Board:      0,
+		PieceMasks: make([]uint64, len(s.Pieces)),
+		Depth:      0,
+		placed:     make([]int, 0, len(s.Pieces)),
+	}
+}
+
+// PieceMapKey returns a canonical string for a piece map so that solutions can
+// be compared and deduplicated.
+func PieceMapKey(pieceMap map[Position]int) string {
+	positions := make([]Position, 0, len(pieceMap))
+	for pos := range pieceMap {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].Row == positions[j].Row {
+			return positions[i].Col < positions[j].Col
+		}
+		return positions[i].Row < positions[j].Row
+	})
+
+	var b strings.Builder
+	for _, pos := range positions {
+		fmt.Fprintf(&b, "%d,%d:%d;", pos.Row, pos.Col, pieceMap[pos])
+	}
+	return b.String()
+}
+
+func (s *CalendarBoardSolver) worker(workerID int, workChan <-chan WorkItem, state *searchState, wg *sync.WaitGroup) {
 	defer wg.Done()
+	defer state.markIdle(workerID)
+
+	search := s.backtrack
+	if s.Strategy == StrategyMostConstrained {
+		search = s.cellSearch
+	}
 
 	for {
 		select {
-		case <-doneChan:
+		case <-state.done:
 			return
 		case work, ok := <-workChan:
 			if !ok {
 				return
 			}
 
-			if s.backtrack(work, targetSize, blockedCells, globalAttempts, resultChan, doneChan, workerID) {
+			if search(&work, state, workerID) {
 				return
 			}
 		}
 	}
 }
 
-func (s *CalendarBoardSolver) backtrack(work WorkItem, targetSize int, blockedCells map[Position]bool, globalAttempts *int64, resultChan chan<- SolveResult, doneChan <-chan bool, workerID int) bool {
-	atomic.AddInt64(globalAttempts, 1)
+func (s *CalendarBoardSolver) backtrack(work *WorkItem, state *searchState, workerID int) bool {
+	state.countNode(workerID, work.Depth)
 
 	// Check if we found a solution
-	if len(work.Board) == targetSize {
-		solution := make([]Position, 0, len(work.Board))
-		for pos := range work.Board {
-			solution = append(solution, pos)
-		}
-
-		select {
-		case resultChan <- SolveResult{
-			Solution: solution,
-			PieceMap: work.PieceMap,
-			Found:    true,
-			WorkerID: workerID,
-		}:
-		case <-doneChan:
-		}
-		return true
+	if work.Board == state.target {
+		return s.reportSolution(state, work, workerID)
 	}
 
 	// Check if we should stop
 	select {
-	case <-doneChan:
+	case <-state.done:
 		return true
 	default:
 	}
 
 	// Find next unused piece
-	nextPieceIndex := -1
-	for i, used := range work.UsedPieces {
-		if !used {
-			nextPieceIndex = i
-			break
-		}
-	}
+	nextPieceIndex := nextUnusedPiece(work.PieceMasks)
 
 	if nextPieceIndex == -1 {
 		return false // All pieces used but board not full
 	}
 
-	// Try all orientations of the next piece
-	orientations := s.getAllOrientations(s.Pieces[nextPieceIndex])
-
-	for _, orientation := range orientations {
-		// Try placing this orientation at all valid positions
-		for row := 0; row < 7; row++ {
-			for col := 0; col < 7; col++ {
-				if s.canPlacePiece(work.Board, orientation, row, col, blockedCells) {
-					newBoard, newPieceMap := s.placePiece(work.Board, work.PieceMap, orientation, row, col, nextPieceIndex+1)
-					newUsedPieces := make([]bool, len(work.UsedPieces))
-					copy(newUsedPieces, work.UsedPieces)
-					newUsedPieces[nextPieceIndex] = true
-
-					newWork := WorkItem{
-						Board:      newBoard,
-						PieceMap:   newPieceMap,
-						UsedPieces: newUsedPieces,
-						Depth:      work.Depth + 1,
-					}
-
-					// Process all work directly to avoid channel complications
-					if s.backtrack(newWork, targetSize, blockedCells, globalAttempts, resultChan, doneChan, workerID) {
-						return true
-					}
-				}
-			}
+	// Try every precomputed placement of the next piece
+	for _, p := range state.placements[nextPieceIndex] {
+		if !s.canPlacePiece(work.Board, p.mask) {
+			continue
+		}
+
+		// The board is updated in place and restored after the recursive call
+		s.placePiece(work, nextPieceIndex, p.mask)
+		stop := !s.pruneAfterPlacement(state, work) && s.backtrack(work, state, workerID)
+		s.removePiece(work, nextPieceIndex)
+		if stop {
+			return true
 		}
 	}
 
 	return false
 }
 
+// reportSolution records a complete board according to the search mode and
+// reports whether the search should stop.
+func (s *CalendarBoardSolver) reportSolution(state *searchState, work *WorkItem, workerID int) bool {
+	if state.mode != searchFirst && !state.canonical(work.PieceMasks) {
+		return false
+	}
+	if state.mode == searchCount {
+		atomic.AddInt64(state.solutionCount, 1)
+		return false
+	}
+
+	result := SolveResult{
+		Solution:   s.positionsFromMask(work.Board),
+		PieceMap:   s.pieceMapFromMasks(work.PieceMasks),
+		Placements: s.placementsFromMasks(work.PieceMasks),
+		Order:      append([]int(nil), work.placed...),
+		Found:      true,
+		WorkerID:   workerID,
+	}
+	select {
+	case state.resultChan <- result:
+	case <-state.done:
+		return true
+	}
+	// Keep walking the tree when every solution is wanted
+	return state.mode == searchFirst
+}
+
+// canonical reports whether identical pieces cover the board in increasing
+// mask order. Each tiling is reached once for every way of assigning its
+// identical pieces, and only this assignment is counted.
+func (state *searchState) canonical(pieceMasks []uint64) bool {
+	for i, j := range state.identical {
+		if j >= 0 && pieceMasks[j] > pieceMasks[i] {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *CalendarBoardSolver) VisualizeSolution(currentDay int, currentMonth string, solution []Position, pieceMap map[Position]int) {
 	fmt.Printf("\nSolution for %d %s:\n", currentDay, currentMonth)
 	fmt.Println("=" + strings.Repeat("=", 29))
+	s.printSolutionGrid(pieceMap)
+	s.printLegend(fmt.Sprintf("Current date (%d %s)", currentDay, currentMonth))
+}
+
+// VisualizeBlockedSolution prints a solution from SolveBlocked.
+func (s *CalendarBoardSolver) VisualizeBlockedSolution(blocked []Position, pieceMap map[Position]int) {
+	fmt.Printf("\nSolution with blocked cells %s:\n", formatCells(blocked))
+	fmt.Println("=" + strings.Repeat("=", 29))
+	s.printSolutionGrid(pieceMap)
+	s.printLegend("Blocked cells")
+}
+
+// printLegend explains the symbols of printSolutionGrid.
+func (s *CalendarBoardSolver) printLegend(free string) {
+	if s.Color {
+		fmt.Printf("\n%s  %s = %s\n", ansiColor(blockedColor, true), ansiReset, free)
+		fmt.Printf("1-%d = Piece numbers\n", len(s.Pieces))
+		return
+	}
+	fmt.Printf("\nX = %s\n", free)
+	fmt.Printf("1-%d = Piece numbers\n", len(s.Pieces))
+	fmt.Println(". = Empty/Invalid positions")
+}
+
+// PrintPlacements lists where each piece of a solution lies and how it is
+// turned, so the solution can be laid out on a real board.
+func (s *CalendarBoardSolver) PrintPlacements(placements []Placement) {
+	fmt.Println("\nPlacements (top-left corner of each piece, row,col):")
+	for _, p := range placements {
+		fmt.Printf("  %s at %d,%d, %s (orientation %d)\n",
+			s.pieceName(p.Piece), p.Anchor.Row, p.Anchor.Col, p.Transform, p.Orientation)
+		fmt.Printf("    cells: %s\n", formatCells(p.Cells))
+	}
+}
+
+// printSolutionGrid prints the board with the piece number on every covered
+// cell and X on the playable cells left free, or draws it with colorGrid.
+func (s *CalendarBoardSolver) printSolutionGrid(pieceMap map[Position]int) {
+	if s.Color {
+		fmt.Print(s.colorGrid(pieceMap))
+		return
+	}
 
 	// Create visual board
-	board := make([][]string, 7)
+	board := make([][]string, s.Rows)
 	for i := range board {
-		board[i] = make([]string, 7)
+		board[i] = make([]string, s.Cols)
 		for j := range board[i] {
 			board[i][j] = "."
 		}
 	}
 
-	// Mark month positions
-	for month, pos := range s.MonthPositions {
-		if month == currentMonth {
-			board[pos.Row][pos.Col] = "X" // Current month (blocked)
-		} else if pieceNum, exists := pieceMap[pos]; exists {
-			board[pos.Row][pos.Col] = fmt.Sprintf("%d", pieceNum) // Show piece number
-		}
-	}
-
-	// Mark day positions
-	for day, pos := range s.DayPositions {
-		if day == currentDay {
-			board[pos.Row][pos.Col] = "X" // Current day (blocked)
-		} else if pieceNum, exists := pieceMap[pos]; exists {
+	// Mark calendar cells: covered ones show their piece, the cells left free
+	// by the date or by SolveBlocked are blocked
+	for _, pos := range s.positionsFromMask(s.calendarMask()) {
+		if pieceNum, exists := pieceMap[pos]; exists {
 			board[pos.Row][pos.Col] = fmt.Sprintf("%d", pieceNum) // Show piece number
+		} else {
+			board[pos.Row][pos.Col] = "X" // Blocked
 		}
 	}
 
-	// Print board
+	// Print board, padding the cells once piece numbers take two digits
+	width := len(fmt.Sprint(len(s.Pieces)))
 	for _, row := range board {
+		for i, cell := range row {
+			row[i] = fmt.Sprintf("%*s", width, cell)
+		}
 		fmt.Println(strings.Join(row, " "))
 	}
-
-	fmt.Printf("\nX = Current date (%d %s)\n", currentDay, currentMonth)
-	fmt.Println("1-8 = Piece numbers")
-	fmt.Println(". = Empty/Invalid positions")
 }
 
 func (s *CalendarBoardSolver) PrintBoardConfiguration() {
@@ -485,9 +915,9 @@ func (s *CalendarBoardSolver) PrintBoardConfiguration() {
 	fmt.Println("=" + strings.Repeat("=", 49))
 
 	// Create board with labels
-	board := make([][]string, 7)
+	board := make([][]string, s.Rows)
 	for i := range board {
-		board[i] = make([]string, 7)
+		board[i] = make([]string, s.Cols)
 		for j := range board[i] {
 			board[i][j] = "   "
 		}
@@ -503,16 +933,26 @@ func (s *CalendarBoardSolver) PrintBoardConfiguration() {
 		board[pos.Row][pos.Col] = fmt.Sprintf("%3d", day)
 	}
 
+	// Fill weekday positions
+	for weekday, pos := range s.WeekdayPositions {
+		board[pos.Row][pos.Col] = fmt.Sprintf("%3s", s.Weekdays[weekday])
+	}
+
+	// Fill unlabelled playable cells
+	for _, pos := range s.FixedCells {
+		board[pos.Row][pos.Col] = "  *"
+	}
+
 	// Print board with row/column indicators
 	fmt.Print("    ")
-	for col := 0; col < 7; col++ {
+	for col := 0; col < s.Cols; col++ {
 		fmt.Printf("%4d", col)
 	}
 	fmt.Println()
 
-	for row := 0; row < 7; row++ {
+	for row := 0; row < s.Rows; row++ {
 		fmt.Printf("%d: ", row)
-		for col := 0; col < 7; col++ {
+		for col := 0; col < s.Cols; col++ {
 			cell := board[row][col]
 			if cell == "   " {
 				fmt.Print("  . ")
@@ -524,35 +964,31 @@ func (s *CalendarBoardSolver) PrintBoardConfiguration() {
 	}
 
 	// Count valid cells
-	validCells := len(s.MonthPositions) + len(s.DayPositions)
+	validCells := len(s.MonthPositions) + len(s.DayPositions) + len(s.WeekdayPositions) + len(s.FixedCells)
+	blockedCells := s.blockedPerDate()
 
 	fmt.Printf("\nBoard Statistics:\n")
-	fmt.Printf("- Total grid size: 7x7 = 49 positions\n")
+	fmt.Printf("- Total grid size: %dx%d = %d positions\n", s.Rows, s.Cols, s.Rows*s.Cols)
 	fmt.Printf("- Valid calendar cells: %d\n", validCells)
 	fmt.Printf("- Month cells: %d\n", len(s.MonthPositions))
 	fmt.Printf("- Day cells: %d\n", len(s.DayPositions))
-	fmt.Printf("- Empty/Invalid positions: %d\n", 49-validCells)
-	fmt.Printf("- Expected filled cells per solution: %d (total - current date)\n", validCells-2)
+	if len(s.WeekdayPositions) > 0 {
+		fmt.Printf("- Weekday cells: %d\n", len(s.WeekdayPositions))
+	}
+	fmt.Printf("- Empty/Invalid positions: %d\n", s.Rows*s.Cols-validCells)
+	fmt.Printf("- Expected filled cells per solution: %d (total - current date)\n", validCells-blockedCells)
 }
 
 func (s *CalendarBoardSolver) PrintPiecesConfiguration() {
 	fmt.Println("\nBRICK PIECES CONFIGURATION:")
 	fmt.Println("=" + strings.Repeat("=", 49))
 
-	pieceNames := []string{
-		"Piece 1: L-shape",
-		"Piece 2: Long L",
-		"Piece 3: Cut Rectangle",
-		"Piece 4: Rectangle",
-		"Piece 5: T-shape",
-		"Piece 6: Z-shape",
-		"Piece 7: P-shape",
-		"Piece 8: Stair Shape",
-	}
-
 	totalCells := 0
 	for i, piece := range s.Pieces {
-		fmt.Printf("\n%s (%d cells):\n", pieceNames[i], len(piece))
+		fmt.Printf("\n%s (%d cells):\n", s.pieceName(i), len(piece))
+		if i < len(s.PieceColors) && s.PieceColors[i] != "" {
+			fmt.Printf("  Color: %s\n", s.PieceColors[i])
+		}
 		totalCells += len(piece)
 
 		// Find bounds
@@ -566,24 +1002,30 @@ func (s *CalendarBoardSolver) PrintPiecesConfiguration() {
 			}
 		}
 
+		// In colour the cells are solid blocks, two columns wide
+		filled, empty, sep := "A", ".", " "
+		if s.Color {
+			filled, empty, sep = ansiColor(s.pieceColor(i), true)+"  "+ansiReset, "  ", ""
+		}
+
 		// Create visual grid
 		grid := make([][]string, maxRow+1)
 		for j := range grid {
 			grid[j] = make([]string, maxCol+1)
 			for k := range grid[j] {
-				grid[j][k] = "."
+				grid[j][k] = empty
 			}
 		}
 
 		// Fill piece positions
 		for _, pos := range piece {
-			grid[pos.Row][pos.Col] = "A"
+			grid[pos.Row][pos.Col] = filled
 		}
 
 		// Print the piece
 		for _, row := range grid {
 			fmt.Print("  ")
-			fmt.Println(strings.Join(row, " "))
+			fmt.Println(strings.TrimRight(strings.Join(row, sep), " "))
 		}
 
 		// Print coordinates
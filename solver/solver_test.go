@@ -0,0 +1,1287 @@
+package solver
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/gif"
+	"image/png"
+	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// checkSolution verifies that a piece map covers every calendar cell except
+// the blocked date exactly once and uses each piece with its own cell count.
+func checkSolution(t *testing.T, s *CalendarBoardSolver, day int, month string, pieceMap map[Position]int) {
+	t.Helper()
+	checkCoverage(t, s, []Position{s.MonthPositions[month], s.DayPositions[day]}, pieceMap)
+}
+
+// checkCoverage verifies that a piece map covers every labelled cell except
+// the blocked ones exactly once and uses each piece with its own cell count.
+func checkCoverage(t *testing.T, s *CalendarBoardSolver, blockedCells []Position, pieceMap map[Position]int) {
+	t.Helper()
+
+	blocked := make(map[Position]bool)
+	for _, pos := range blockedCells {
+		blocked[pos] = true
+	}
+
+	cells := make([]Position, 0)
+	for _, pos := range s.MonthPositions {
+		cells = append(cells, pos)
+	}
+	for _, pos := range s.DayPositions {
+		cells = append(cells, pos)
+	}
+	for _, pos := range s.WeekdayPositions {
+		cells = append(cells, pos)
+	}
+	cells = append(cells, s.FixedCells...)
+
+	pieceCells := make(map[int]int)
+	for _, pos := range cells {
+		pieceNum, covered := pieceMap[pos]
+		if blocked[pos] {
+			if covered {
+				t.Errorf("blocked cell %v is covered by piece %d", pos, pieceNum)
+			}
+			continue
+		}
+		if !covered {
+			t.Errorf("cell %v is not covered", pos)
+			continue
+		}
+		pieceCells[pieceNum]++
+	}
+
+	if len(pieceMap) != len(cells)-len(blocked) {
+		t.Errorf("piece map covers %d cells, expected %d", len(pieceMap), len(cells)-len(blocked))
+	}
+	for i, piece := range s.Pieces {
+		if pieceCells[i+1] != len(piece) {
+			t.Errorf("piece %d covers %d cells, expected %d", i+1, pieceCells[i+1], len(piece))
+		}
+	}
+}
+
+func TestSolveParallel(t *testing.T) {
+	s := NewCalendarBoardSolver()
+
+	testCases := []struct {
+		day   int
+		month string
+	}{
+		{1, "Янв"},
+		{15, "Март"},
+		{29, "Фев"},
+		{31, "Дек"},
+	}
+
+	for _, tc := range testCases {
+		result := s.SolveParallel(tc.day, tc.month)
+		if !result.Found {
+			t.Fatalf("SolveParallel(%d, %s): no solution found", tc.day, tc.month)
+		}
+		if len(result.Solution) != len(result.PieceMap) {
+			t.Errorf("SolveParallel(%d, %s): %d solution cells, %d piece map cells", tc.day, tc.month, len(result.Solution), len(result.PieceMap))
+		}
+		checkSolution(t, s, tc.day, tc.month, result.PieceMap)
+	}
+}
+
+func TestSolveAllMatchesCount(t *testing.T) {
+	s := NewCalendarBoardSolver()
+
+	all := s.SolveAll(1, "Янв")
+	count := s.CountSolutions(1, "Янв")
+
+	if all.Count == 0 {
+		t.Fatal("SolveAll(1, Янв): no solutions found")
+	}
+	if int64(all.Count) != count.Count {
+		t.Errorf("SolveAll found %d solutions, CountSolutions counted %d", all.Count, count.Count)
+	}
+	if len(count.WorkerNodes) != runtime.NumCPU() {
+		t.Errorf("CountSolutions reported %d workers, expected %d", len(count.WorkerNodes), runtime.NumCPU())
+	}
+	var workerTotal int64
+	for _, nodes := range count.WorkerNodes {
+		workerTotal += nodes
+	}
+	if workerTotal == 0 || workerTotal > count.Attempts {
+		t.Errorf("workers visited %d nodes out of %d attempts", workerTotal, count.Attempts)
+	}
+	if len(all.Solutions) != all.Count {
+		t.Errorf("SolveAll returned %d solutions, Count is %d", len(all.Solutions), all.Count)
+	}
+
+	seen := make(map[string]bool)
+	for _, solution := range all.Solutions {
+		key := PieceMapKey(solution.PieceMap)
+		if seen[key] {
+			t.Errorf("duplicate solution %s", key)
+		}
+		seen[key] = true
+		checkSolution(t, s, 1, "Янв", solution.PieceMap)
+	}
+}
+
+func TestPlacementTable(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	calendar := s.calendarMask()
+
+	table := s.placementTable()
+	if len(table) != len(s.Pieces) {
+		t.Fatalf("placement table has %d pieces, expected %d", len(table), len(s.Pieces))
+	}
+
+	for i, placements := range table {
+		if len(placements) == 0 {
+			t.Errorf("piece %d has no placements", i+1)
+		}
+		seen := make(map[uint64]bool)
+		for _, p := range placements {
+			if p.mask&^calendar != 0 {
+				t.Errorf("piece %d placement at %v leaves the calendar", i+1, p.anchor)
+			}
+			if len(s.positionsFromMask(p.mask)) != len(s.Pieces[i]) {
+				t.Errorf("piece %d placement at %v covers %d cells", i+1, p.anchor, len(s.positionsFromMask(p.mask)))
+			}
+			if seen[p.mask] {
+				t.Errorf("piece %d has duplicate placement at %v", i+1, p.anchor)
+			}
+			seen[p.mask] = true
+		}
+	}
+
+	blocked := s.cellBit(s.MonthPositions["Май"]) | s.cellBit(s.DayPositions[17])
+	for i, placements := range s.placementsFor(calendar &^ blocked) {
+		for _, p := range placements {
+			if p.mask&blocked != 0 {
+				t.Errorf("piece %d placement at %v covers a blocked cell", i+1, p.anchor)
+			}
+		}
+	}
+}
+
+func TestStrategiesAgree(t *testing.T) {
+	backtrack := NewCalendarBoardSolver()
+	strategies := []*CalendarBoardSolver{
+		NewCalendarBoardSolver(WithStrategy(StrategyDLX)),
+		NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained)),
+	}
+
+	for _, month := range []string{"Янв", "Июнь", "Дек"} {
+		for _, day := range []int{1, 17, 31} {
+			expected := backtrack.CountSolutions(day, month).Count
+
+			for _, s := range strategies {
+				if actual := s.CountSolutions(day, month).Count; actual != expected {
+					t.Errorf("CountSolutions(%d, %s): %s counted %d, backtrack counted %d", day, month, s.Strategy, actual, expected)
+				}
+
+				result := s.SolveParallel(day, month)
+				if result.Found != (expected > 0) {
+					t.Fatalf("SolveParallel(%d, %s): %s found %v, expected %v", day, month, s.Strategy, result.Found, expected > 0)
+				}
+				if result.Found {
+					checkSolution(t, s, day, month, result.PieceMap)
+				}
+			}
+		}
+	}
+
+	expected := backtrack.CountSolutions(1, "Янв").Count
+	for _, s := range strategies {
+		if all := s.SolveAll(1, "Янв"); int64(all.Count) != expected {
+			t.Errorf("SolveAll(1, Янв): %s found %d solutions, expected %d", s.Strategy, all.Count, expected)
+		}
+	}
+}
+
+func TestSolveContextCancellation(t *testing.T) {
+	s := NewCalendarBoardSolver()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := s.SolveContext(ctx, 1, "Янв")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("SolveContext with cancelled context: expected context.Canceled, got %v", err)
+	}
+	if result.Found {
+		t.Error("SolveContext with cancelled context: expected no solution")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	count, err := s.CountSolutionsContext(ctx, 1, "Янв")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("CountSolutionsContext with deadline: expected context.DeadlineExceeded, got %v", err)
+	}
+	if count.Count >= 64 {
+		t.Errorf("CountSolutionsContext with deadline: counted %d solutions, expected a partial count", count.Count)
+	}
+
+	result, err = s.SolveContext(context.Background(), 1, "Янв")
+	if err != nil || !result.Found {
+		t.Errorf("SolveContext without deadline: found %v, error %v", result.Found, err)
+	}
+}
+
+func TestSplitWork(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	state, err := s.prepareSolve(calendarDate{day: 1, month: "Янв", weekday: noWeekday})
+	if err != nil {
+		t.Fatalf("prepareSolve(1, Янв): %v", err)
+	}
+	var attempts int64
+	state.globalAttempts = &attempts
+
+	items := s.splitWork(state, 64)
+	if len(items) < 64 {
+		t.Fatalf("splitWork returned %d items, expected at least 64", len(items))
+	}
+
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if item.Depth == 0 {
+			t.Fatal("splitWork returned the unexpanded root")
+		}
+		if state.hasDeadRegion(item.Board, item.PieceMasks) {
+			t.Errorf("splitWork returned a dead item %s", PieceMapKey(s.pieceMapFromMasks(item.PieceMasks)))
+		}
+		key := PieceMapKey(s.pieceMapFromMasks(item.PieceMasks))
+		if seen[key] {
+			t.Errorf("splitWork returned duplicate item %s", key)
+		}
+		seen[key] = true
+	}
+	if state.pruned.Load() == 0 {
+		t.Error("splitWork pruned no placements")
+	}
+}
+
+func TestSolverOptions(t *testing.T) {
+	s := NewCalendarBoardSolver(
+		WithWorkers(3),
+		WithTimeout(time.Minute),
+		WithStrategy(StrategyBacktrack),
+		WithOrientations(OrientationsRotateOnly),
+	)
+
+	if s.WorkerCount() != 3 || s.Timeout != time.Minute {
+		t.Errorf("options not applied: workers %d, timeout %v", s.WorkerCount(), s.Timeout)
+	}
+	for i, piece := range s.Pieces {
+		if n := len(s.getAllOrientations(piece)); n > 4 {
+			t.Errorf("piece %d has %d orientations without flipping", i+1, n)
+		}
+	}
+
+	result := s.CountSolutions(1, "Янв")
+	if len(result.WorkerNodes) != 3 {
+		t.Errorf("CountSolutions used %d workers, expected 3", len(result.WorkerNodes))
+	}
+	if all := NewCalendarBoardSolver().CountSolutions(1, "Янв"); result.Count > all.Count {
+		t.Errorf("rotation-only count %d exceeds count with flips %d", result.Count, all.Count)
+	}
+}
+
+func TestPieceAreaError(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	s.Pieces = s.Pieces[:7]
+
+	_, err := s.SolveContext(context.Background(), 1, "Янв")
+	var areaErr *PieceAreaError
+	if !errors.As(err, &areaErr) {
+		t.Fatalf("SolveContext with 7 pieces: expected *PieceAreaError, got %v", err)
+	}
+	if areaErr.PieceCells != 36 || areaErr.TargetCells != 41 {
+		t.Errorf("PieceAreaError: got %d piece cells and %d target cells", areaErr.PieceCells, areaErr.TargetCells)
+	}
+
+	if _, err := s.CountSolutionsContext(context.Background(), 1, "Янв"); !errors.As(err, &areaErr) {
+		t.Errorf("CountSolutionsContext with 7 pieces: expected *PieceAreaError, got %v", err)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var mu sync.Mutex
+	events := make([]Progress, 0)
+
+	s := NewCalendarBoardSolver(WithWorkers(2), WithProgress(time.Millisecond, func(p Progress) {
+		mu.Lock()
+		events = append(events, p)
+		mu.Unlock()
+	}))
+	result := s.CountSolutions(1, "Янв")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(events) < 2 {
+		t.Fatalf("expected periodic and final progress events, got %d", len(events))
+	}
+	last := events[len(events)-1]
+	if !last.Done || last.Attempts != result.Attempts {
+		t.Errorf("final event: done %v with %d attempts, solve made %d", last.Done, last.Attempts, result.Attempts)
+	}
+	for i, event := range events {
+		if len(event.Workers) != 2 {
+			t.Errorf("event %d reports %d workers", i, len(event.Workers))
+		}
+		if i > 0 && event.Attempts < events[i-1].Attempts {
+			t.Errorf("event %d: attempts went back from %d to %d", i, events[i-1].Attempts, event.Attempts)
+		}
+		if event.Done != (i == len(events)-1) {
+			t.Errorf("event %d: unexpected done %v", i, event.Done)
+		}
+	}
+	for _, worker := range last.Workers {
+		if !worker.Idle {
+			t.Errorf("worker %d still busy in the final event", worker.ID)
+		}
+	}
+}
+
+func TestPruning(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyBacktrack, StrategyMostConstrained} {
+		pruned := NewCalendarBoardSolver(WithStrategy(strategy)).CountSolutions(1, "Янв")
+		full := NewCalendarBoardSolver(WithStrategy(strategy), WithPruning(false)).CountSolutions(1, "Янв")
+
+		if pruned.Count != full.Count {
+			t.Errorf("%s: counted %d solutions with pruning, %d without", strategy, pruned.Count, full.Count)
+		}
+		if pruned.Pruned == 0 || full.Pruned != 0 {
+			t.Errorf("%s: pruned %d placements with pruning, %d without", strategy, pruned.Pruned, full.Pruned)
+		}
+		if pruned.Attempts >= full.Attempts {
+			t.Errorf("%s: %d attempts with pruning, %d without", strategy, pruned.Attempts, full.Attempts)
+		}
+	}
+}
+
+func TestHasDeadRegion(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	state, err := s.prepareSolve(calendarDate{day: 1, month: "Янв", weekday: noWeekday})
+	if err != nil {
+		t.Fatalf("prepareSolve(1, Янв): %v", err)
+	}
+
+	work := s.initialWorkItem()
+	if state.hasDeadRegion(work.Board, work.PieceMasks) {
+		t.Error("empty board reported as dead")
+	}
+
+	// Fill everything except the "Фев" cell, leaving a region of one cell
+	work.Board = state.target &^ s.cellBit(s.MonthPositions["Фев"])
+	work.PieceMasks[0] = work.Board
+	if !state.hasDeadRegion(work.Board, work.PieceMasks) {
+		t.Error("isolated single cell not reported as dead")
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	// The answer is the lexicographically first solution, whatever the
+	// strategy, the number of workers or the database
+	all := NewCalendarBoardSolver().SolveAll(17, "Май")
+	if len(all.Solutions) == 0 {
+		t.Fatal("no solution for 17 Май")
+	}
+	expected := PieceMapKey(all.Solutions[0].PieceMap)
+
+	db, err := EmbeddedSolutionDB()
+	if err != nil {
+		t.Fatalf("EmbeddedSolutionDB: %v", err)
+	}
+	solvers := map[string]*CalendarBoardSolver{"database": NewCalendarBoardSolver(WithSolutionDB(db), WithDeterministic(true))}
+	for _, strategy := range []Strategy{StrategyBacktrack, StrategyMostConstrained, StrategyDLX} {
+		solvers[strategy.String()] = NewCalendarBoardSolver(WithStrategy(strategy), WithWorkers(4), WithDeterministic(true))
+	}
+	for name, s := range solvers {
+		for i := 0; i < 3; i++ {
+			result := s.SolveParallel(17, "Май")
+			if key := PieceMapKey(result.PieceMap); key != expected {
+				t.Errorf("%s run %d: got %s, expected %s", name, i, key, expected)
+			}
+			if result.Precomputed != (name == "database") {
+				t.Errorf("%s run %d: precomputed %v", name, i, result.Precomputed)
+			}
+		}
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	solve := func(seed uint64) string {
+		s := NewCalendarBoardSolver(WithRandomSeed(seed), WithDeterministic(true))
+		result := s.SolveParallel(1, "Янв")
+		if !result.Found {
+			t.Fatalf("seed %d: no solution for 1 Янв", seed)
+		}
+		checkSolution(t, s, 1, "Янв", result.PieceMap)
+		return PieceMapKey(result.PieceMap)
+	}
+
+	if solve(42) != solve(42) {
+		t.Error("the same seed gave different solutions")
+	}
+
+	distinct := make(map[string]bool)
+	for seed := uint64(0); seed < 10; seed++ {
+		distinct[solve(seed)] = true
+	}
+	if len(distinct) < 2 {
+		t.Errorf("10 seeds gave %d distinct solutions", len(distinct))
+	}
+
+	count := NewCalendarBoardSolver(WithRandomSeed(7)).CountSolutions(1, "Янв")
+	if expected := NewCalendarBoardSolver().CountSolutions(1, "Янв"); count.Count != expected.Count {
+		t.Errorf("shuffled search counted %d solutions, expected %d", count.Count, expected.Count)
+	}
+}
+
+func TestSolutionDB(t *testing.T) {
+	db, err := EmbeddedSolutionDB()
+	if err != nil {
+		t.Fatalf("EmbeddedSolutionDB: %v", err)
+	}
+	if db.Len() != 366 {
+		t.Errorf("embedded database has %d dates, expected 366", db.Len())
+	}
+
+	live := NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained))
+	s := NewCalendarBoardSolver(WithSolutionDB(db))
+	if db.Fingerprint != s.Fingerprint() {
+		t.Fatalf("embedded database fingerprint %016x, solver %016x", db.Fingerprint, s.Fingerprint())
+	}
+
+	for monthIndex, month := range s.Months {
+		for _, day := range []int{1, 15, 29} {
+			stored, ok := db.Count(monthIndex+1, day)
+			if !ok {
+				t.Fatalf("database has no entry for %d %s", day, month)
+			}
+			if count := s.CountSolutions(day, month); !count.Precomputed || count.Count != stored {
+				t.Errorf("CountSolutions(%d, %s): precomputed %v, count %d, stored %d", day, month, count.Precomputed, count.Count, stored)
+			}
+			if expected := live.CountSolutions(day, month).Count; stored != expected {
+				t.Errorf("database counts %d solutions for %d %s, live search %d", stored, day, month, expected)
+			}
+
+			result := s.SolveParallel(day, month)
+			if !result.Precomputed || !result.Found {
+				t.Fatalf("SolveParallel(%d, %s): precomputed %v, found %v", day, month, result.Precomputed, result.Found)
+			}
+			checkSolution(t, s, day, month, result.PieceMap)
+		}
+	}
+
+	// A database only answers for the board and pieces it was built for
+	rotateOnly := NewCalendarBoardSolver(WithSolutionDB(db), WithOrientations(OrientationsRotateOnly))
+	if result := rotateOnly.SolveParallel(1, "Янв"); result.Precomputed {
+		t.Error("database used for a solver with a different fingerprint")
+	}
+}
+
+func TestSolutionDBRoundTrip(t *testing.T) {
+	db, err := EmbeddedSolutionDB()
+	if err != nil {
+		t.Fatalf("EmbeddedSolutionDB: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := db.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), embeddedSolutionDB) {
+		t.Error("rewriting the embedded database changed it")
+	}
+
+	decoded, err := ReadSolutionDB(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadSolutionDB: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, db) {
+		t.Error("decoded database differs from the original")
+	}
+
+	for _, data := range [][]byte{nil, []byte("XXXX"), buf.Bytes()[:len(buf.Bytes())/2]} {
+		if _, err := ReadSolutionDB(bytes.NewReader(data)); err == nil {
+			t.Errorf("ReadSolutionDB accepted %d bytes of corrupt data", len(data))
+		}
+	}
+}
+
+// solutionDBFile encodes a database file whose body holds the fingerprint
+// followed by the given uvarints, valid or not.
+func solutionDBFile(t *testing.T, fingerprint uint64, fields ...uint64) []byte {
+	t.Helper()
+	body := binary.LittleEndian.AppendUint64(nil, fingerprint)
+	for _, field := range fields {
+		body = binary.AppendUvarint(body, field)
+	}
+	var file bytes.Buffer
+	file.WriteString(solutionDBMagic)
+	file.Write(binary.AppendUvarint(nil, solutionDBVersion))
+	w, err := flate.NewWriter(&file, flate.BestSpeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(body)
+	w.Close()
+	return file.Bytes()
+}
+
+func TestSolutionDBCorrupt(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []uint64 // pieces, entries, then month, day, count, stored, masks...
+	}{
+		{"huge piece count", []uint64{1 << 40, 1}},
+		{"no pieces", []uint64{0, 0}},
+		{"too many pieces", []uint64{maxBoardCells + 1, 0}},
+		{"month 13", []uint64{2, 1, 13, 1, 1, 0}},
+		{"day 0", []uint64{2, 1, 1, 0, 1, 0}},
+		{"day 32", []uint64{2, 1, 1, 32, 1, 0}},
+		{"empty mask", []uint64{2, 1, 1, 1, 1, 1, 0b11, 0}},
+		{"overlapping masks", []uint64{2, 1, 1, 1, 1, 1, 0b11, 0b10}},
+		{"truncated", []uint64{2, 3, 1, 1}},
+	}
+	for _, tt := range tests {
+		if _, err := ReadSolutionDB(bytes.NewReader(solutionDBFile(t, 1, tt.fields...))); err == nil {
+			t.Errorf("%s: ReadSolutionDB accepted a corrupt body", tt.name)
+		}
+	}
+}
+
+func TestSolutionDBIllegalMasks(t *testing.T) {
+	s := NewCalendarBoardSolver(WithWorkers(1))
+
+	// Single cells fit every header check but are no legal placement of any piece
+	fields := []uint64{uint64(len(s.Pieces)), 1, 1, 1, 1, 1}
+	for i := range s.Pieces {
+		fields = append(fields, 1<<(i+2))
+	}
+	db, err := ReadSolutionDB(bytes.NewReader(solutionDBFile(t, s.Fingerprint(), fields...)))
+	if err != nil {
+		t.Fatalf("ReadSolutionDB: %v", err)
+	}
+
+	s = NewCalendarBoardSolver(WithWorkers(1), WithSolutionDB(db))
+	result := s.SolveParallel(1, "Янв")
+	if !result.Found || result.Precomputed {
+		t.Fatalf("expected a searched solution, got found=%v precomputed=%v", result.Found, result.Precomputed)
+	}
+	if len(result.Placements) != len(s.Pieces) {
+		t.Errorf("%d placements, expected %d", len(result.Placements), len(s.Pieces))
+	}
+}
+
+func TestWeekdayBoard(t *testing.T) {
+	s := NewWeekdayBoardSolver()
+	if cells := len(s.MonthPositions) + len(s.DayPositions) + len(s.WeekdayPositions); cells != 50 {
+		t.Errorf("weekday board has %d cells, expected 50", cells)
+	}
+	if s.calendarMask()>>(s.Rows*s.Cols) != 0 {
+		t.Error("weekday cells lie outside the board")
+	}
+
+	// 1-7 January 2025 run from Wednesday to Tuesday, so every weekday cell is
+	// blocked once
+	for day := 1; day <= 7; day++ {
+		date := time.Date(2025, time.January, day, 0, 0, 0, 0, time.UTC)
+		result := s.SolveDate(date)
+		if !result.Found {
+			t.Fatalf("SolveDate(%s): no solution found", s.FormatDate(date))
+		}
+		blocked := []Position{s.MonthPositions["Янв"], s.DayPositions[day], s.WeekdayPositions[date.Weekday()]}
+		checkCoverage(t, s, blocked, result.PieceMap)
+	}
+
+	if _, err := s.SolveContext(context.Background(), 1, "Янв"); !errors.Is(err, ErrWeekdayRequired) {
+		t.Errorf("SolveContext on the weekday board: expected ErrWeekdayRequired, got %v", err)
+	}
+
+	// On the classic board SolveDate ignores the weekday
+	classic := NewCalendarBoardSolver()
+	date := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC)
+	if result := classic.SolveDate(date); !result.Found {
+		t.Error("SolveDate on the classic board: no solution found")
+	} else {
+		checkSolution(t, classic, 15, "Март", result.PieceMap)
+	}
+}
+
+func TestParseBoard(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	for i, month := range s.Months {
+		if expected := (Position{i / 6, i % 6}); s.MonthPositions[month] != expected {
+			t.Errorf("%s at %v, expected %v", month, s.MonthPositions[month], expected)
+		}
+	}
+	for day := 1; day <= 31; day++ {
+		if expected := (Position{2 + (day-1)/7, (day - 1) % 7}); s.DayPositions[day] != expected {
+			t.Errorf("day %d at %v, expected %v", day, s.DayPositions[day], expected)
+		}
+	}
+
+	// The classic board mirrored along its diagonal has the same solutions
+	// mirrored, because the pieces may be flipped
+	var classic, transposed [7][7]string
+	file, err := boardFiles.ReadFile("boards/classic.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	row := 0
+	for _, line := range strings.Split(string(file), "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		copy(classic[row][:], strings.Fields(line))
+		row++
+	}
+	var text strings.Builder
+	for r := range transposed {
+		for c := range transposed[r] {
+			fmt.Fprintf(&text, "%s ", classic[c][r])
+		}
+		text.WriteString("\n")
+	}
+	board, err := ParseBoard(strings.NewReader(text.String()))
+	if err != nil {
+		t.Fatalf("ParseBoard(transposed classic): %v", err)
+	}
+	mirrored := NewBoardSolver(board, WithStrategy(StrategyMostConstrained))
+	if pos := mirrored.MonthPositions["Июль"]; pos != (Position{0, 1}) {
+		t.Errorf("Июль at %v on the transposed board", pos)
+	}
+	count := mirrored.CountSolutions(1, "Янв").Count
+	if expected := s.CountSolutions(1, "Янв").Count; count != expected {
+		t.Errorf("transposed board has %d solutions for 1 Янв, expected %d", count, expected)
+	}
+
+	invalid := map[string]string{
+		"ragged rows":        "M1 M2\nM3\n",
+		"unknown cell":       strings.Replace(text.String(), "D5 ", "X5 ", 1),
+		"month out of range": strings.Replace(text.String(), "M12 ", "M13 ", 1),
+		"duplicate cell":     strings.Replace(text.String(), "D5 ", "D6 ", 1),
+		"zero-padded number": strings.Replace(text.String(), "D5 ", "D05 ", 1),
+		"signed number":      strings.Replace(text.String(), "M3 ", "M+3 ", 1),
+		"missing day":        strings.Replace(text.String(), "D31 ", ". ", 1),
+		"some weekdays":      strings.Replace(text.String(), ". ", "W3 ", 1),
+		"too many cells":     strings.Repeat(". ", 9) + "\n" + text.String(),
+	}
+	for name, definition := range invalid {
+		if _, err := ParseBoard(strings.NewReader(definition)); err == nil {
+			t.Errorf("ParseBoard accepted a board with %s", name)
+		}
+	}
+}
+
+func TestParsePieces(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	if len(s.Pieces) != 8 || len(s.PieceColors) != 8 || s.PieceNames[2] != "Cut Rectangle" {
+		t.Fatalf("classic pieces: %d pieces, names %v", len(s.Pieces), s.PieceNames)
+	}
+	if expected := (Piece{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {1, 2}}); !reflect.DeepEqual(s.Pieces[2], expected) {
+		t.Errorf("Cut Rectangle = %v, expected %v", s.Pieces[2], expected)
+	}
+
+	// Two L-shapes instead of the Cut Rectangle; swapping them must not
+	// count as a different solution
+	set, err := ParsePieces(strings.NewReader(`
+piece L-shape color=#EF4444 count=2
+X..
+X..
+XXX
+piece Long L
+X.
+X.
+X.
+XX
+piece Rectangle
+XXX
+XXX
+piece T-shape
+X.X
+XXX
+piece Z-shape
+.XX
+.X.
+XX.
+piece P-shape
+X.
+X.
+XX
+X.
+piece Stair Shape
+.X
+.X
+XX
+X.
+`))
+	if err != nil {
+		t.Fatalf("ParsePieces: %v", err)
+	}
+	if len(set.Pieces) != 8 || set.Names[1] != "L-shape" || set.Colors[0] != "#ef4444" || set.Colors[2] != "" {
+		t.Fatalf("parsed %d pieces, names %v, colors %v", len(set.Pieces), set.Names, set.Colors)
+	}
+
+	s = NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained))
+	if err := s.SetPieces(set); err != nil {
+		t.Fatalf("SetPieces: %v", err)
+	}
+	all := s.SolveAll(1, "Янв")
+	if all.Count == 0 {
+		t.Fatal("no solutions for 1 Янв with two L-shapes")
+	}
+	if count := s.CountSolutions(1, "Янв").Count; count != int64(all.Count) {
+		t.Errorf("CountSolutions = %d, SolveAll found %d", count, all.Count)
+	}
+	tilings := make(map[string]bool)
+	for _, solution := range all.Solutions {
+		merged := make(map[Position]int)
+		for pos, pieceNum := range solution.PieceMap {
+			merged[pos] = max(pieceNum, 2) // Pieces 1 and 2 are the L-shapes
+		}
+		key := PieceMapKey(merged)
+		if tilings[key] {
+			t.Fatal("SolveAll returned the same tiling with the L-shapes swapped")
+		}
+		tilings[key] = true
+	}
+
+	s = NewCalendarBoardSolver()
+	set.Pieces = set.Pieces[1:]
+	var areaErr *PieceAreaError
+	if err := s.SetPieces(set); !errors.As(err, &areaErr) || areaErr.PieceCells != 36 || areaErr.TargetCells != 41 {
+		t.Errorf("SetPieces with a missing piece: %v", err)
+	}
+
+	invalid := map[string]string{
+		"no pieces":         "# nothing here\n",
+		"shape first":       "XX\npiece Bar\nXX\n",
+		"no name":           "piece color=#ffffff\nX\n",
+		"bad color":         "piece Bar color=red\nXX\n",
+		"bad count":         "piece Bar count=0\nXX\n",
+		"unknown attribute": "piece Bar size=2\nXX\n",
+		"empty shape":       "piece Bar\npiece Dot\nX\n",
+		"unknown cell":      "piece Bar\nXO\n",
+		"disconnected":      "piece Bar\nX.X\n",
+		"indented row":      "piece Stair\nXX\n XX\n",
+	}
+	for name, definition := range invalid {
+		if _, err := ParsePieces(strings.NewReader(definition)); err == nil {
+			t.Errorf("ParsePieces accepted pieces with %s", name)
+		}
+	}
+}
+
+func TestParseMonth(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected time.Month
+		hasError bool
+	}{
+		{"Янв", time.January, false},
+		{"1", time.January, false},
+		{"12", time.December, false},
+		{"янв", time.January, false},
+		{"март", time.March, false},
+		{"Сен", time.September, false},
+		{"Ноя", time.November, false},
+		{"Сентябрь", time.September, false},
+		{"Mar", time.March, false},
+		{"sept", time.September, false},
+		{"JULY", time.July, false},
+		{"Ма", 0, true},
+		{"Ju", 0, true},
+		{"invalid", 0, true},
+		{"13", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			actual, err := ParseMonth(tc.input)
+			if (err != nil) != tc.hasError {
+				t.Errorf("ParseMonth(%q): expected error %v, got %v", tc.input, tc.hasError, err)
+			}
+			if actual != tc.expected {
+				t.Errorf("ParseMonth(%q): expected %v, got %v", tc.input, tc.expected, actual)
+			}
+		})
+	}
+
+	var ambiguous *AmbiguousMonthError
+	if _, err := ParseMonth("Ма"); !errors.As(err, &ambiguous) || !reflect.DeepEqual(ambiguous.Matches, []string{"Март", "Май"}) {
+		t.Errorf("ParseMonth(\"Ма\") = %v, expected Март and Май", err)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	if _, err := LookupLocale("xx"); err == nil {
+		t.Error("LookupLocale accepted an unknown locale")
+	}
+	en, err := LookupLocale("EN")
+	if err != nil {
+		t.Fatalf("LookupLocale(EN): %v", err)
+	}
+
+	s := NewWeekdayBoardSolver(WithLocale(en))
+	if s.Months[8] != "Sep" || s.Weekdays[time.Friday] != "Fri" {
+		t.Errorf("English labels: %v %v", s.Months, s.Weekdays)
+	}
+	if pos, expected := s.MonthPositions["Sep"], NewWeekdayBoardSolver().MonthPositions["Сент"]; pos != expected {
+		t.Errorf("Sep at %v, expected %v", pos, expected)
+	}
+	date := time.Date(2026, time.April, 3, 0, 0, 0, 0, time.UTC)
+	if label := s.FormatDate(date); label != "3 Apr Fri" {
+		t.Errorf("FormatDate = %q, expected \"3 Apr Fri\"", label)
+	}
+
+	// The database is keyed by month number, so it serves every locale
+	db, err := EmbeddedSolutionDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := NewCalendarBoardSolver(WithLocale(en), WithSolutionDB(db)).CountSolutions(1, "Jan")
+	if !result.Precomputed || result.Count != 64 {
+		t.Errorf("CountSolutions(1, Jan) = %d, precomputed %v", result.Count, result.Precomputed)
+	}
+}
+
+func TestSolveMonthDay(t *testing.T) {
+	s := NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained))
+
+	result, err := s.SolveMonthDayContext(context.Background(), time.February, 29)
+	if err != nil || !result.Found {
+		t.Fatalf("SolveMonthDayContext(29 Фев) = found %v, %v", result.Found, err)
+	}
+	checkSolution(t, s, 29, "Фев", result.PieceMap)
+
+	invalid := []struct {
+		month time.Month
+		day   int
+		err   string
+	}{
+		{time.February, 30, "invalid date: 30 Фев"},
+		{time.April, 31, "invalid date: 31 Апр"},
+		{time.January, 0, "invalid date: 0 Янв"},
+		{13, 1, "invalid date: 1 13"},
+	}
+	for _, tc := range invalid {
+		_, err := s.SolveMonthDayContext(context.Background(), tc.month, tc.day)
+		var dateErr *InvalidDateError
+		if !errors.As(err, &dateErr) || err.Error() != tc.err {
+			t.Errorf("SolveMonthDayContext(%d, %d) = %v, expected %q", tc.month, tc.day, err, tc.err)
+		}
+	}
+
+	// The label-based methods validate the same way
+	for _, date := range []struct {
+		day   int
+		month string
+	}{{31, "Фев"}, {32, "Янв"}, {1, "Foo"}} {
+		_, err := s.SolveContext(context.Background(), date.day, date.month)
+		var dateErr *InvalidDateError
+		if !errors.As(err, &dateErr) {
+			t.Errorf("SolveContext(%d, %s) = %v, expected an InvalidDateError", date.day, date.month, err)
+		}
+	}
+
+	weekday := NewWeekdayBoardSolver()
+	if _, err := weekday.SolveMonthDayContext(context.Background(), time.March, 1); !errors.Is(err, ErrWeekdayRequired) {
+		t.Errorf("SolveMonthDayContext on the weekday board = %v", err)
+	}
+	if err := weekday.CheckDate(2026, time.February, 29); err == nil || err.Error() != "invalid date: 29 Фев 2026" {
+		t.Errorf("CheckDate(29 Feb 2026) = %v", err)
+	}
+	if err := weekday.CheckDate(2028, time.February, 29); err != nil {
+		t.Errorf("CheckDate(29 Feb 2028) = %v", err)
+	}
+}
+
+func TestSolveBlocked(t *testing.T) {
+	s := NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained))
+
+	// Blocking the cells of a date is the same puzzle as solving the date
+	date := []Position{s.MonthPositions["Март"], s.DayPositions[15]}
+	count, err := s.CountSolutionsBlockedContext(context.Background(), date)
+	if err != nil {
+		t.Fatalf("CountSolutionsBlockedContext: %v", err)
+	}
+	if expected := s.CountSolutions(15, "Март").Count; count.Count != expected {
+		t.Errorf("blocked 15 Март has %d solutions, expected %d", count.Count, expected)
+	}
+
+	// Two days and no month
+	blocked := []Position{s.DayPositions[24], s.DayPositions[25]}
+	result, err := s.SolveBlockedContext(context.Background(), blocked)
+	if err != nil || !result.Found {
+		t.Fatalf("SolveBlockedContext(24, 25) = found %v, %v", result.Found, err)
+	}
+	checkCoverage(t, s, blocked, result.PieceMap)
+
+	var areaErr *PieceAreaError
+	if _, err := s.SolveBlockedContext(context.Background(), blocked[:1]); !errors.As(err, &areaErr) || areaErr.TargetCells != 42 {
+		t.Errorf("SolveBlockedContext with one cell = %v, expected a PieceAreaError", err)
+	}
+	for _, cell := range []Position{{0, 6}, {-1, 0}, {7, 0}, {0, 7}} {
+		var cellErr *InvalidCellError
+		_, err := s.SolveBlockedContext(context.Background(), []Position{cell, s.DayPositions[1]})
+		if !errors.As(err, &cellErr) || cellErr.Cell != cell {
+			t.Errorf("SolveBlockedContext(%v) = %v, expected an InvalidCellError", cell, err)
+		}
+	}
+
+	// The weekday board accepts blocked cells without a weekday
+	weekday := NewWeekdayBoardSolver(WithStrategy(StrategyMostConstrained))
+	blocked = []Position{weekday.MonthPositions["Янв"], weekday.DayPositions[1], weekday.WeekdayPositions[time.Monday]}
+	if result := weekday.SolveBlocked(blocked); !result.Found {
+		t.Error("no solution on the weekday board for 1 Янв Пн as blocked cells")
+	}
+}
+
+func TestPlacements(t *testing.T) {
+	db, err := EmbeddedSolutionDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*CalendarBoardSolver{
+		NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained)),
+		NewCalendarBoardSolver(WithStrategy(StrategyDLX)),
+		NewCalendarBoardSolver(WithSolutionDB(db)),
+	} {
+		result := s.SolveParallel(5, "Июнь")
+		if !result.Found || len(result.Placements) != len(s.Pieces) {
+			t.Fatalf("%v: found %v with %d placements", s.Strategy, result.Found, len(result.Placements))
+		}
+
+		for i, p := range result.Placements {
+			if p.Piece != i {
+				t.Errorf("placement %d is for piece %d", i, p.Piece)
+			}
+			for _, cell := range p.Cells {
+				if result.PieceMap[cell] != i+1 {
+					t.Errorf("piece %d covers %v, but the piece map has %d", i+1, cell, result.PieceMap[cell])
+				}
+			}
+
+			// Turning the piece as described and moving it to the anchor
+			// must give the covered cells
+			shape := s.Pieces[i]
+			if p.Transform.Flipped {
+				shape = s.flipHorizontal(shape)
+			}
+			for range p.Transform.Rotation / 90 {
+				shape = s.rotatePiece90(shape)
+			}
+			shape = s.normalizePiece(shape)
+			if !reflect.DeepEqual(shape, s.getAllOrientations(s.Pieces[i])[p.Orientation]) {
+				t.Errorf("piece %d: %s does not give orientation %d", i+1, p.Transform, p.Orientation)
+			}
+			for j := range shape {
+				shape[j].Row += p.Anchor.Row
+				shape[j].Col += p.Anchor.Col
+			}
+			if !reflect.DeepEqual([]Position(shape), p.Cells) {
+				t.Errorf("piece %d %s at %v covers %v, expected %v", i+1, p.Transform, p.Anchor, shape, p.Cells)
+			}
+		}
+	}
+
+	for transform, expected := range map[Transform]string{
+		{}:                            "as drawn",
+		{Rotation: 270}:               "rotated 270° clockwise",
+		{Flipped: true}:               "flipped",
+		{Rotation: 90, Flipped: true}: "flipped, rotated 90° clockwise",
+	} {
+		if transform.String() != expected {
+			t.Errorf("%#v.String() = %q, expected %q", transform, transform.String(), expected)
+		}
+	}
+}
+
+func TestOutline(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cells []Position
+		loops [][]point
+	}{
+		{"cell", []Position{{0, 0}}, [][]point{{{0, 0}, {1, 0}, {1, 1}, {0, 1}}}},
+		{"L", []Position{{0, 0}, {1, 0}, {1, 1}}, [][]point{{{0, 0}, {1, 0}, {1, 1}, {2, 1}, {2, 2}, {0, 2}}}},
+		{"corners touch", []Position{{0, 0}, {1, 1}}, [][]point{
+			{{0, 0}, {1, 0}, {1, 1}, {0, 1}},
+			{{1, 1}, {2, 1}, {2, 2}, {1, 2}},
+		}},
+		{"ring", []Position{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 1}, {2, 2}}, [][]point{
+			{{0, 0}, {3, 0}, {3, 3}, {0, 3}},
+			{{1, 1}, {1, 2}, {2, 2}, {2, 1}},
+		}},
+	}
+	for _, tc := range testCases {
+		if loops := outline(tc.cells); !reflect.DeepEqual(loops, tc.loops) {
+			t.Errorf("outline(%s) = %v, expected %v", tc.name, loops, tc.loops)
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	result := s.SolveParallel(1, "Янв")
+
+	var buf bytes.Buffer
+	if err := s.WriteSVG(&buf, result, RenderOptions{CellSize: 40, Title: "1 Янв <&>"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The output must be well-formed XML
+	decoder := xml.NewDecoder(bytes.NewReader(buf.Bytes()))
+	paths, blocked := 0, 0
+	var texts []string
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, buf.String())
+		}
+		switch element := token.(type) {
+		case xml.StartElement:
+			for _, attr := range element.Attr {
+				if element.Name.Local == "rect" && attr.Name.Local == "fill" && attr.Value == blockedColor {
+					blocked++
+				}
+			}
+			if element.Name.Local == "path" {
+				paths++
+			}
+		case xml.CharData:
+			texts = append(texts, string(element))
+		}
+	}
+
+	// One path per piece and one for the board outline
+	if paths != len(s.Pieces)+1 {
+		t.Errorf("%d paths, expected %d", paths, len(s.Pieces)+1)
+	}
+	if blocked != 2 {
+		t.Errorf("%d blocked cells highlighted, expected 2", blocked)
+	}
+	text := strings.Join(texts, " ")
+	for _, expected := range []string{"1 Янв <&>", "Янв", "31", "Piece 8: Stair Shape"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("SVG text does not contain %q", expected)
+		}
+	}
+}
+
+func TestWritePNG(t *testing.T) {
+	s := NewCalendarBoardSolver()
+	result := s.SolveParallel(1, "Янв")
+	palette := []string{"#FF0000", "#00ff00", "#0000ff"}
+
+	var buf bytes.Buffer
+	opts := RenderOptions{CellSize: 40, Title: "1 Янв", Palette: palette}
+	if err := s.WritePNG(&buf, result, opts); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("invalid PNG: %v", err)
+	}
+
+	// Same layout as the SVG: a margin of a quarter cell, half a cell for the title
+	width, height := s.Cols*40+2*10, s.Rows*40+10+20+10
+	if size := img.Bounds().Size(); size.X != width || size.Y != height {
+		t.Fatalf("image is %dx%d, expected %dx%d", size.X, size.Y, width, height)
+	}
+
+	// Sample every cell away from its outline and its label
+	for row := range s.Rows {
+		for col := range s.Cols {
+			pos := Position{row, col}
+			if s.cellBit(pos)&s.calendarMask() == 0 {
+				continue
+			}
+			expected := blockedColor
+			if pieceNum, covered := result.PieceMap[pos]; covered {
+				expected = strings.ToLower(palette[(pieceNum-1)%len(palette)])
+			}
+			r, g, b, _ := img.At(10+col*40+10, 30+row*40+10).RGBA()
+			if got := fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8); got != expected {
+				t.Errorf("cell %v is %s, expected %s", pos, got, expected)
+			}
+		}
+	}
+
+	opts.Palette = []string{"red"}
+	if err := s.WritePNG(io.Discard, result, opts); err == nil {
+		t.Error("expected an error for an invalid palette colour")
+	}
+}
+
+func TestFontCoversLabels(t *testing.T) {
+	for _, locale := range locales {
+		labels := append(locale.Months[:], locale.Weekdays[:]...)
+		labels = append(labels, locale.MonthNames[:]...)
+		for _, label := range labels {
+			for _, r := range label {
+				if glyph(r) == font5x7['?'] {
+					t.Errorf("%s locale: no glyph for %q in %s", locale.Name, r, label)
+				}
+			}
+		}
+	}
+	for r, g := range font5x7 {
+		for _, row := range g {
+			if len(row) != glyphWidth || strings.Trim(row, "#.") != "" {
+				t.Errorf("glyph %q has a malformed row %q", r, row)
+			}
+		}
+	}
+}
+
+func TestColorGrid(t *testing.T) {
+	s := NewCalendarBoardSolver(WithColor(true))
+	result := s.SolveParallel(15, "Апр")
+	if !result.Found {
+		t.Fatal("no solution for 15 Апр")
+	}
+	grid := s.colorGrid(result.PieceMap)
+
+	// Only the two free cells are highlighted, showing their labels
+	if n := strings.Count(grid, ansiColor(blockedColor, true)); n != 2 {
+		t.Errorf("%d cells highlighted, expected 2", n)
+	}
+	plain := regexp.MustCompile("\x1b\\[[0-9;]*m").ReplaceAllString(grid, "")
+	lines := strings.Split(strings.TrimSuffix(plain, "\n"), "\n")
+	if len(lines) != 2*s.Rows+1 {
+		t.Fatalf("%d lines, expected %d:\n%s", len(lines), 2*s.Rows+1, plain)
+	}
+	if !strings.HasPrefix(lines[0], "┌") || !strings.HasPrefix(lines[len(lines)-1], "└") {
+		t.Errorf("board outline is not closed:\n%s", plain)
+	}
+	if !strings.Contains(plain, "│ Апр  │") || !strings.Contains(plain, "│  15  │") {
+		t.Errorf("free cells are not framed with their labels:\n%s", plain)
+	}
+
+	// Every piece number appears once per cell of the piece
+	for i, piece := range s.Pieces {
+		if n := strings.Count(plain, fmt.Sprintf("  %d   ", i+1)); n != len(piece) {
+			t.Errorf("piece %d shown on %d cells, expected %d", i+1, n, len(piece))
+		}
+	}
+}
+
+func TestSolveOrder(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyBacktrack, StrategyMostConstrained, StrategyDLX} {
+		s := NewCalendarBoardSolver(WithStrategy(strategy), WithWorkers(2), WithDeterministic(true))
+		result := s.SolveParallel(15, "Март")
+		if !result.Found {
+			t.Fatalf("%s: no solution for 15 Март", strategy)
+		}
+
+		// Every piece is placed exactly once
+		seen := make(map[int]bool)
+		for _, piece := range result.Order {
+			if piece < 0 || piece >= len(s.Pieces) || seen[piece] {
+				t.Fatalf("%s: invalid order %v", strategy, result.Order)
+			}
+			seen[piece] = true
+		}
+		if len(seen) != len(s.Pieces) {
+			t.Errorf("%s: order %v does not place all %d pieces", strategy, result.Order, len(s.Pieces))
+		}
+
+		// Backtracking places the pieces by index
+		if strategy == StrategyBacktrack {
+			for i, piece := range result.Order {
+				if piece != i {
+					t.Errorf("backtrack placed piece %d at step %d", piece, i)
+				}
+			}
+		}
+	}
+}
+
+func TestWriteGIF(t *testing.T) {
+	s := NewCalendarBoardSolver(WithStrategy(StrategyMostConstrained), WithDeterministic(true))
+	result := s.SolveParallel(1, "Янв")
+
+	var buf bytes.Buffer
+	opts := RenderOptions{CellSize: 30, Delay: 500 * time.Millisecond}
+	if err := s.WriteGIF(&buf, result, opts); err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatalf("invalid GIF: %v", err)
+	}
+
+	// The empty board, then one frame per piece, the last one held longer
+	if len(anim.Image) != len(s.Pieces)+1 {
+		t.Fatalf("%d frames, expected %d", len(anim.Image), len(s.Pieces)+1)
+	}
+	if anim.Delay[0] != 50 || anim.Delay[len(anim.Delay)-1] != 200 {
+		t.Errorf("delays %v, expected 50 and a final 200", anim.Delay)
+	}
+
+	// Each frame adds the next piece in search order, with the date marked throughout
+	colors, _ := s.pieceColors(opts)
+	colorAt := func(frame int, pos Position) string {
+		r, g, b, _ := anim.Image[frame].At(7+pos.Col*30+5, 7+pos.Row*30+5).RGBA()
+		return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+	}
+	placed := make(map[int]bool)
+	for frame := range anim.Image {
+		if frame > 0 {
+			placed[result.Order[frame-1]] = true
+		}
+		for _, pos := range s.positionsFromMask(s.calendarMask()) {
+			expected := blockedColor
+			if pieceNum, covered := result.PieceMap[pos]; covered {
+				expected = cellColor
+				if placed[pieceNum-1] {
+					expected = colors[pieceNum-1]
+				}
+			}
+			if got := colorAt(frame, pos); got != expected {
+				t.Fatalf("frame %d: cell %v is %s, expected %s", frame, pos, got, expected)
+			}
+		}
+	}
+}
+
+// SolveAll forwards solutions without deduplicating them, so every strategy
+// must reach each tiling exactly once, also when the work is split.
+func TestSolveAllDistinct(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyBacktrack, StrategyMostConstrained, StrategyDLX} {
+		s := NewCalendarBoardSolver(WithStrategy(strategy), WithWorkers(3))
+		all := s.SolveAll(1, "Янв")
+		seen := make(map[string]bool)
+		for _, solution := range all.Solutions {
+			key := PieceMapKey(solution.PieceMap)
+			if seen[key] {
+				t.Fatalf("%s: duplicate solution %s", strategy, key)
+			}
+			seen[key] = true
+		}
+		if count := s.CountSolutions(1, "Янв"); int64(all.Count) != count.Count {
+			t.Errorf("%s: SolveAll found %d solutions, CountSolutions %d", strategy, all.Count, count.Count)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package solver
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Colours of the SVG and PNG renderings.
+const (
+	backgroundColor = "#f8fafc"
+	cellColor       = "#ffffff"
+	gridColor       = "#cbd5e1"
+	blockedColor    = "#fde68a"
+	outlineColor    = "#1f2937"
+	labelColor      = "#111827"
+)
+
+// WriteSVG draws the board with its labels as an SVG image. If result holds
+// a solution, every piece is drawn as one outlined polygon in its colour and
+// the cells left free, the blocked date, are highlighted.
+func (s *CalendarBoardSolver) WriteSVG(w io.Writer, result SolveResult, opts RenderOptions) error {
+	colors, err := s.pieceColors(opts)
+	if err != nil {
+		return err
+	}
+	l := s.layout(opts)
+	cell, margin, top, width, height := l.cell, l.margin, l.top, l.width, l.height
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif">`+"\n",
+		width, height, width, height)
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", width, height, backgroundColor)
+	if opts.Title != "" {
+		fmt.Fprintf(bw, `<text x="%d" y="%d" font-size="%d" font-weight="bold" text-anchor="middle" fill="%s">%s</text>`+"\n",
+			width/2, margin+cell/4, cell*3/10, labelColor, escapeXML(opts.Title))
+	}
+
+	// Board cells, with the cells a solution leaves free highlighted
+	playable := s.positionsFromMask(s.calendarMask())
+	fmt.Fprintf(bw, `<g stroke="%s" stroke-width="1">`+"\n", gridColor)
+	for _, pos := range playable {
+		fill := cellColor
+		if _, covered := result.PieceMap[pos]; result.Found && !covered {
+			fill = blockedColor
+		}
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			margin+pos.Col*cell, top+pos.Row*cell, cell, cell, fill)
+	}
+	fmt.Fprintln(bw, `</g>`)
+
+	// Pieces, then the outline of the whole board
+	stroke := max(cell/20, 1)
+	if result.Found {
+		for i, cells := range s.pieceCells(result.PieceMap) {
+			if len(cells) == 0 {
+				continue
+			}
+			fmt.Fprintf(bw, `<path d="%s" fill="%s" fill-rule="evenodd" stroke="%s" stroke-width="%d" stroke-linejoin="round"><title>%s</title></path>`+"\n",
+				svgPath(outline(cells), l.corner), colors[i], outlineColor, stroke, escapeXML(s.pieceName(i)))
+		}
+	}
+	fmt.Fprintf(bw, `<path d="%s" fill="none" stroke="%s" stroke-width="%d" stroke-linejoin="round"/>`+"\n",
+		svgPath(outline(playable), l.corner), outlineColor, stroke*2)
+
+	// Labels stay readable on top of the pieces
+	fmt.Fprintf(bw, `<g font-size="%d" text-anchor="middle" dominant-baseline="central">`+"\n", cell*7/25)
+	labels := s.cellLabels()
+	for _, pos := range playable {
+		label, ok := labels[pos]
+		if !ok {
+			continue
+		}
+		x, y := margin+pos.Col*cell+cell/2, top+pos.Row*cell+cell/2
+		if _, covered := result.PieceMap[pos]; result.Found && covered {
+			fmt.Fprintf(bw, `<text x="%d" y="%d" fill="#ffffff" fill-opacity="0.75">%s</text>`+"\n", x, y, escapeXML(label))
+		} else {
+			fmt.Fprintf(bw, `<text x="%d" y="%d" fill="%s" font-weight="bold">%s</text>`+"\n", x, y, labelColor, escapeXML(label))
+		}
+	}
+	fmt.Fprintln(bw, `</g>`)
+	fmt.Fprintln(bw, `</svg>`)
+	return bw.Flush()
+}
+
+// svgPath converts outline loops into SVG path data, one subpath per loop.
+func svgPath(loops [][]point, offset func(point) (int, int)) string {
+	var d strings.Builder
+	for _, loop := range loops {
+		for i, p := range loop {
+			x, y := offset(p)
+			if i == 0 {
+				fmt.Fprintf(&d, "M%d %d", x, y)
+			} else {
+				fmt.Fprintf(&d, "L%d %d", x, y)
+			}
+		}
+		d.WriteString("Z")
+	}
+	return d.String()
+}
+
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
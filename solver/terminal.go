@@ -0,0 +1,128 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const ansiReset = "\x1b[0m"
+
+// ansiColor returns the escape sequence for a 24-bit "#rrggbb" text colour,
+// or background colour when background is set.
+func ansiColor(hex string, background bool) string {
+	c := parseColor(hex)
+	layer := 38
+	if background {
+		layer = 48
+	}
+	return fmt.Sprintf("\x1b[%d;2;%d;%d;%dm", layer, c.R, c.G, c.B)
+}
+
+// boxCorners holds the box-drawing character for every combination of lines
+// meeting at a grid corner, indexed by up | down<<1 | left<<2 | right<<3.
+var boxCorners = [16]string{
+	" ", "╵", "╷", "│", "╴", "┘", "┐", "┤",
+	"╶", "└", "┌", "├", "─", "┴", "┬", "┼",
+}
+
+// colorGrid draws a solution for the terminal: every piece in its colour,
+// framed with box-drawing lines and showing its number, and the cells left
+// free highlighted with their labels.
+func (s *CalendarBoardSolver) colorGrid(pieceMap map[Position]int) string {
+	labels := s.cellLabels()
+	width := len(fmt.Sprint(len(s.Pieces)))
+	for _, label := range labels {
+		width = max(width, utf8.RuneCountInString(label))
+	}
+	width += 2 // A space on both sides
+
+	// region tells the cells apart: a piece number, a negative number unique
+	// to each free cell, or 0 off the board
+	calendar := s.calendarMask()
+	region := func(row, col int) int {
+		if row < 0 || col < 0 || row >= s.Rows || col >= s.Cols {
+			return 0
+		}
+		pos := Position{row, col}
+		if s.cellBit(pos)&calendar == 0 {
+			return 0
+		}
+		if pieceNum, covered := pieceMap[pos]; covered {
+			return pieceNum
+		}
+		return -1 - (row*s.Cols + col)
+	}
+
+	// fill paints the gaps between the cells of one piece in its colour
+	fill := func(text string, r int) string {
+		if r > 0 {
+			return ansiColor(s.pieceColor(r-1), true) + text + ansiReset
+		}
+		return text
+	}
+	center := func(text string) string {
+		pad := width - utf8.RuneCountInString(text)
+		return strings.Repeat(" ", pad/2) + text + strings.Repeat(" ", pad-pad/2)
+	}
+
+	var out strings.Builder
+	for row := 0; row <= s.Rows; row++ {
+		// The line above the row, then the row itself
+		var line strings.Builder
+		for col := 0; col <= s.Cols; col++ {
+			upLeft, upRight := region(row-1, col-1), region(row-1, col)
+			downLeft, downRight := region(row, col-1), region(row, col)
+			corner := 0
+			for bit, border := range []bool{upLeft != upRight, downLeft != downRight, upLeft != downLeft, upRight != downRight} {
+				if border {
+					corner |= 1 << bit
+				}
+			}
+			inside := 0
+			if corner == 0 {
+				inside = upLeft // Also the other three cells
+			}
+			line.WriteString(fill(boxCorners[corner], inside))
+			if col == s.Cols {
+				break
+			}
+			if upRight != downRight {
+				line.WriteString(strings.Repeat("─", width))
+			} else {
+				line.WriteString(fill(strings.Repeat(" ", width), downRight))
+			}
+		}
+		out.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+		if row == s.Rows {
+			break
+		}
+
+		line.Reset()
+		for col := 0; col <= s.Cols; col++ {
+			left, right := region(row, col-1), region(row, col)
+			if left != right {
+				line.WriteString("│")
+			} else {
+				line.WriteString(fill(" ", right))
+			}
+			if col == s.Cols {
+				break
+			}
+			switch {
+			case right > 0:
+				line.WriteString(ansiColor(s.pieceColor(right-1), true) + "\x1b[1;97m" + center(fmt.Sprint(right)) + ansiReset)
+			case right < 0:
+				label, ok := labels[Position{row, col}]
+				if !ok {
+					label = "X"
+				}
+				line.WriteString(ansiColor(blockedColor, true) + ansiColor(labelColor, false) + "\x1b[1m" + center(label) + ansiReset)
+			default:
+				line.WriteString(strings.Repeat(" ", width))
+			}
+		}
+		out.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+	}
+	return out.String()
+}
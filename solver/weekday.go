@@ -0,0 +1,102 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrWeekdayRequired is returned when a day and month are solved on a board
+// that also has weekday cells. Use SolveDate and friends there instead.
+var ErrWeekdayRequired = errors.New("this board also blocks a weekday, solve a full date")
+
+// noWeekday marks a calendarDate without a weekday.
+const noWeekday time.Weekday = -1
+
+// calendarDate names the cells a date blocks: a day, a month label and, on
+// weekday boards, a weekday. Puzzles from SolveBlocked list their cells in
+// blocked instead and leave the date fields empty.
+type calendarDate struct {
+	day     int
+	month   string
+	weekday time.Weekday
+	blocked []Position
+}
+
+// dateOf converts a time into the board labels for its date.
+func (s *CalendarBoardSolver) dateOf(date time.Time) calendarDate {
+	return calendarDate{
+		day:     date.Day(),
+		month:   s.Months[date.Month()-1],
+		weekday: date.Weekday(),
+	}
+}
+
+func (date calendarDate) weekdayLabel(s *CalendarBoardSolver) string {
+	if date.weekday == noWeekday || len(s.Weekdays) == 0 {
+		return ""
+	}
+	return s.Weekdays[date.weekday]
+}
+
+// blockedCells returns the cells a date leaves uncovered.
+func (s *CalendarBoardSolver) blockedCells(date calendarDate) (uint64, error) {
+	if date.blocked != nil {
+		return s.blockedMask(date.blocked)
+	}
+	if err := s.checkDate(date); err != nil {
+		return 0, err
+	}
+	blocked := s.cellBit(s.MonthPositions[date.month]) | s.cellBit(s.DayPositions[date.day])
+	if len(s.WeekdayPositions) > 0 {
+		if date.weekday == noWeekday {
+			return 0, ErrWeekdayRequired
+		}
+		blocked |= s.cellBit(s.WeekdayPositions[date.weekday])
+	}
+	return blocked, nil
+}
+
+// NewWeekdayBoardSolver returns a solver for the weekday edition of the
+// puzzle: the classic month and day cells plus a row and a half of weekdays
+// on an 8x7 board, so every date blocks three cells. It comes with its own
+// set of seven pentominoes and three tetrominoes covering 47 cells.
+func NewWeekdayBoardSolver(opts ...Option) *CalendarBoardSolver {
+	return NewBoardSolver(builtinBoard("weekday"), opts...)
+}
+
+// SolveDate finds a single solution for a full date, blocking its weekday on
+// weekday boards, and gives up after the configured Timeout.
+func (s *CalendarBoardSolver) SolveDate(date time.Time) SolveResult {
+	ctx, cancel := s.timeoutContext(context.Background())
+	defer cancel()
+
+	result, err := s.SolveDateContext(ctx, date)
+	s.logUnreturnedError(err, date.Day(), s.Months[date.Month()-1])
+	return result
+}
+
+// SolveDateContext is like SolveContext for a full date.
+func (s *CalendarBoardSolver) SolveDateContext(ctx context.Context, date time.Time) (SolveResult, error) {
+	return s.solveFirst(ctx, s.dateOf(date))
+}
+
+// SolveAllStreamDateContext is like SolveAllStreamContext for a full date.
+func (s *CalendarBoardSolver) SolveAllStreamDateContext(ctx context.Context, date time.Time, out chan<- SolveResult) (SolveAllResult, error) {
+	return s.solveAllStream(ctx, s.dateOf(date), out)
+}
+
+// CountSolutionsDateContext is like CountSolutionsContext for a full date.
+func (s *CalendarBoardSolver) CountSolutionsDateContext(ctx context.Context, date time.Time) (CountResult, error) {
+	return s.countSolutions(ctx, s.dateOf(date))
+}
+
+// FormatDate returns the board labels of a date, e.g. "3 Апр" or "3 Апр Пт" on
+// weekday boards.
+func (s *CalendarBoardSolver) FormatDate(date time.Time) string {
+	if len(s.WeekdayPositions) == 0 {
+		return fmt.Sprintf("%d %s", date.Day(), s.Months[date.Month()-1])
+	}
+	return fmt.Sprintf("%d %s %s", date.Day(), s.Months[date.Month()-1], s.Weekdays[date.Weekday()])
+}
@@ -0,0 +1,18 @@
+//go:build js
+
+package solver
+
+import "runtime"
+
+// yieldInterval is how many nodes a worker searches between yields.
+const yieldInterval = 4096
+
+// maybeYield lets other goroutines run every yieldInterval nodes. WebAssembly
+// runs Go on a single thread that is never preempted, so without it the
+// progress ticker and the timers behind deadlines would wait for the search
+// to finish.
+func maybeYield(nodes int64) {
+	if nodes%yieldInterval == 0 {
+		runtime.Gosched()
+	}
+}
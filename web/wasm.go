@@ -4,67 +4,223 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"syscall/js"
+	"time"
 
 	"puzzle_solver/solver"
 )
 
+// solutionDB answers dates instantly; dates it does not hold are solved live.
+var solutionDB *solver.SolutionDB
+
 func main() {
 	fmt.Println("Hello, WebAssembly!")
+	db, err := solver.EmbeddedSolutionDB()
+	if err != nil {
+		fmt.Println("Solution database unavailable:", err)
+	}
+	solutionDB = db
 	js.Global().Set("solveCalendar", js.FuncOf(solveCalendar))
+	js.Global().Set("solveWeekdayCalendar", js.FuncOf(solveWeekdayCalendar))
+	js.Global().Set("solveBlocked", js.FuncOf(solveBlocked))
 	// Keep the Go program alive for JS calls
 	select {}
 }
 
-// solveCalendar is a wrapper for the solver logic to be called from JS.
+// solveCalendar is a wrapper for the solver logic to be called from JS. The
+// month is a number (1-12) or a name in any locale. Optional further arguments
+// are a function that receives progress updates and a locale name for the
+// board labels.
 func solveCalendar(this js.Value, args []js.Value) interface{} {
-	if len(args) != 2 {
-		return "Invalid number of arguments"
+	if len(args) < 2 || len(args) > 4 {
+		return errorJSON(fmt.Errorf("invalid number of arguments: %d", len(args)))
 	}
 
 	day := args[0].Int()
-	monthIndex := args[1].Int() // Expecting month as 1-12
+	month, err := monthArg(args[1])
+	if err != nil {
+		return errorJSON(err)
+	}
 
-	month, err := monthFromIndex(monthIndex)
+	opts, err := solverOptions(args[2:])
 	if err != nil {
-		return err.Error()
+		return errorJSON(err)
+	}
+	s := solver.NewCalendarBoardSolver(opts...)
+	if err := s.CheckDate(0, month, day); err != nil {
+		return errorJSON(err)
+	}
+	result := s.SolveMonthDay(month, day)
+	return resultJSON(s, result)
+}
+
+// solveWeekdayCalendar solves a full date (day, month, year) on the weekday
+// board, which also blocks the weekday. It takes the same optional arguments
+// as solveCalendar.
+func solveWeekdayCalendar(this js.Value, args []js.Value) interface{} {
+	if len(args) < 3 || len(args) > 5 {
+		return errorJSON(fmt.Errorf("invalid number of arguments: %d", len(args)))
 	}
 
-	s := solver.NewCalendarBoardSolver()
-	result := s.SolveParallel(day, month)
+	day, year := args[0].Int(), args[2].Int()
+	month, err := monthArg(args[1])
+	if err != nil {
+		return errorJSON(err)
+	}
+	opts, err := solverOptions(args[3:])
+	if err != nil {
+		return errorJSON(err)
+	}
+	s := solver.NewWeekdayBoardSolver(opts...)
+	if err := s.CheckDate(year, month, day); err != nil {
+		return errorJSON(err)
+	}
+	result := s.SolveDate(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+	return resultJSON(s, result)
+}
+
+// solveBlocked covers every cell of a board ("classic" or "weekday") except
+// the blocked ones, given as an array of [row, col] pairs. It takes the same
+// optional arguments as solveCalendar.
+func solveBlocked(this js.Value, args []js.Value) interface{} {
+	if len(args) < 2 || len(args) > 4 {
+		return errorJSON(fmt.Errorf("invalid number of arguments: %d", len(args)))
+	}
+
+	opts, err := solverOptions(args[2:])
+	if err != nil {
+		return errorJSON(err)
+	}
+	var s *solver.CalendarBoardSolver
+	switch board := args[0].String(); board {
+	case "classic":
+		s = solver.NewCalendarBoardSolver(opts...)
+	case "weekday":
+		s = solver.NewWeekdayBoardSolver(opts...)
+	default:
+		return errorJSON(fmt.Errorf("invalid board: %s", board))
+	}
+
+	blocked := make([]solver.Position, args[1].Length())
+	for i := range blocked {
+		cell := args[1].Index(i)
+		blocked[i] = solver.Position{Row: cell.Index(0).Int(), Col: cell.Index(1).Int()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Timeout)
+	defer cancel()
+	result, err := s.SolveBlockedContext(ctx, blocked)
+	if err != nil && ctx.Err() == nil {
+		return errorJSON(err)
+	}
+	return resultJSON(s, result)
+}
+
+// monthArg reads a month given as a number or as a name in any locale.
+func monthArg(v js.Value) (time.Month, error) {
+	if v.Type() == js.TypeString {
+		return solver.ParseMonth(v.String())
+	}
+	return solver.ParseMonth(fmt.Sprint(v.Int()))
+}
+
+// solverOptions builds the options shared by all entry points from the
+// optional JS arguments: a progress callback and a locale name.
+func solverOptions(args []js.Value) ([]solver.Option, error) {
+	opts := []solver.Option{solver.WithSolutionDB(solutionDB)}
+	for _, arg := range args {
+		switch arg.Type() {
+		case js.TypeFunction:
+			onProgress := arg
+			opts = append(opts, solver.WithProgress(solver.DefaultProgressInterval, func(p solver.Progress) {
+				onProgress.Invoke(map[string]interface{}{
+					"elapsed":        p.Elapsed.String(),
+					"attempts":       p.Attempts,
+					"nodesPerSecond": p.NodesPerSecond,
+					"depth":          p.Depth,
+					"done":           p.Done,
+				})
+			}))
+		case js.TypeString:
+			locale, err := solver.LookupLocale(arg.String())
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, solver.WithLocale(locale))
+		}
+	}
+	return opts, nil
+}
+
+// errorJSON reports a failure to the page as {"error": "..."}, so that every
+// entry point returns JSON, such as the message of an InvalidDateError.
+func errorJSON(err error) interface{} {
+	errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+	return string(errJSON)
+}
+
+// resultJSON describes the board layout and the solution for the page, which
+// draws any board from the labels and piece map.
+func resultJSON(s *solver.CalendarBoardSolver, result solver.SolveResult) interface{} {
+	key := func(pos solver.Position) string {
+		return fmt.Sprintf("%d,%d", pos.Row, pos.Col)
+	}
+
+	labels := make(map[string]string)
+	for month, pos := range s.MonthPositions {
+		labels[key(pos)] = month
+	}
+	for day, pos := range s.DayPositions {
+		labels[key(pos)] = fmt.Sprint(day)
+	}
+	for weekday, pos := range s.WeekdayPositions {
+		labels[key(pos)] = s.Weekdays[weekday]
+	}
 
 	pieceMapForJS := make(map[string]int)
 	for pos, pieceNum := range result.PieceMap {
-		key := fmt.Sprintf("%d,%d", pos.Row, pos.Col)
-		pieceMapForJS[key] = pieceNum
+		pieceMapForJS[key(pos)] = pieceNum
+	}
+
+	placements := make([]map[string]interface{}, len(result.Placements))
+	for i, p := range result.Placements {
+		cells := make([][2]int, len(p.Cells))
+		for j, cell := range p.Cells {
+			cells[j] = [2]int{cell.Row, cell.Col}
+		}
+		placements[i] = map[string]interface{}{
+			"piece":       p.Piece,
+			"orientation": p.Orientation,
+			"rotation":    p.Transform.Rotation,
+			"flipped":     p.Transform.Flipped,
+			"transform":   p.Transform.String(),
+			"anchor":      [2]int{p.Anchor.Row, p.Anchor.Col},
+			"cells":       cells,
+		}
 	}
 
 	resultMap := map[string]interface{}{
 		"solution":    result.Solution,
 		"pieceMap":    pieceMapForJS,
+		"placements":  placements,
 		"found":       result.Found,
 		"solveTime":   result.SolveTime.String(),
 		"attempts":    result.Attempts,
+		"precomputed": result.Precomputed,
+		"rows":        s.Rows,
+		"cols":        s.Cols,
+		"labels":      labels,
+		"pieceNames":  s.PieceNames,
+		"pieceColors": s.PieceColors,
 	}
 
 	resultJSON, err := json.Marshal(resultMap)
 	if err != nil {
-		return "Error marshalling result to JSON"
+		return errorJSON(err)
 	}
 
 	return string(resultJSON)
 }
-
-// monthFromIndex converts a 1-based month index to its string representation.
-func monthFromIndex(index int) (string, error) {
-	if index < 1 || index > 12 {
-		return "", fmt.Errorf("invalid month index: %d", index)
-	}
-	months := []string{
-		"Янв", "Фев", "Март", "Апр", "Май", "Июнь",
-		"Июль", "Авг", "Сент", "Окт", "Нояб", "Дек",
-	}
-	return months[index-1], nil
-}
\ No newline at end of file
@@ -0,0 +1,28 @@
+// Runs the solver off the main thread. A solve keeps the Go runtime busy until
+// it finishes, so on the page itself the browser could not repaint and progress
+// would never show. Messages in: {id, fn, args}, where fn is one of the
+// functions registered by wasm.go and args its arguments without the progress
+// callback. Messages out: {id, progress} while solving, then {id, result} with
+// the JSON string returned by wasm.go, {"error": "..."} on failure.
+importScripts("wasm_exec.js");
+
+const go = new Go();
+const ready = WebAssembly.instantiateStreaming(fetch("main.wasm"), go.importObject).then((result) => {
+    go.run(result.instance);
+    postMessage({ ready: true });
+});
+
+onmessage = async (event) => {
+    await ready;
+    const { id, fn, args } = event.data;
+    const progress = (p) => postMessage({ id, progress: p });
+
+    // The progress callback goes right after the required arguments
+    const required = { solveCalendar: 2, solveWeekdayCalendar: 3, solveBlocked: 2 }[fn];
+    const callArgs = [...args.slice(0, required), progress, ...args.slice(required)];
+    try {
+        postMessage({ id, result: self[fn](...callArgs) });
+    } catch (error) {
+        postMessage({ id, result: JSON.stringify({ error: error.message }) });
+    }
+};